@@ -0,0 +1,89 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+)
+
+func dataSourceFirewallExclusions() *schema.Resource {
+
+	return &schema.Resource{
+
+		Read: dataSourceFirewallExclusionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return the members whose name matches this regular expression",
+			},
+			"moids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"members": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"moid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"object_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceFirewallExclusionsRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	getAPI := newGetFirewallExclusions()
+	err := nsxclient.Do(getAPI)
+	if err != nil {
+		return err
+	}
+
+	err = checkerr(getAPI)
+	if err != nil {
+		return err
+	}
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	moids := make([]string, 0)
+	members := make([]map[string]interface{}, 0)
+	for _, member := range getAPI.GetResponse().Members {
+		if nameRegex != nil && !nameRegex.MatchString(member.Name) {
+			continue
+		}
+		moids = append(moids, member.MOID)
+		members = append(members, map[string]interface{}{
+			"moid":        member.MOID,
+			"name":        member.Name,
+			"object_type": member.ObjectType,
+		})
+	}
+
+	d.SetId(firewallExclusionListID)
+	d.Set("moids", moids)
+	return d.Set("members", members)
+}
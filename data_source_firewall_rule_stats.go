@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+)
+
+func dataSourceFirewallRuleStats() *schema.Resource {
+
+	return &schema.Resource{
+
+		Read: dataSourceFirewallRuleStatsRead,
+
+		Schema: map[string]*schema.Schema{
+			"sectionid": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"layer": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "LAYER3",
+				ValidateFunc: validation.StringInSlice([]string{
+					"LAYER3",
+					"LAYER2",
+				}, false),
+			},
+			"ruleid": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "ID of the rule to get the statistics of, every rule of the section when not set",
+			},
+			"rules": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hit_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"packet_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"byte_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"session_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"timestamp": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Time of the statistics, in milliseconds since the epoch",
+						},
+					},
+				},
+			},
+			"unused_rule_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "IDs of the rules that never matched any traffic",
+			},
+		},
+	}
+}
+
+func dataSourceFirewallRuleStatsRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+	sectionID := d.Get("sectionid").(int)
+
+	section, _, err := getFirewallSection(d.Get("layer").(string), sectionID, nsxclient)
+	if err != nil {
+		return err
+	}
+	if section == nil {
+		return fmt.Errorf("Firewall section %d not found", sectionID)
+	}
+
+	ruleID, ruleFilter := d.GetOk("ruleid")
+
+	rules := make([]map[string]interface{}, 0)
+	unusedRuleIDs := make([]int, 0)
+	for _, sectionRule := range section.Rules {
+		if ruleFilter && sectionRule.ID != ruleID.(int) {
+			continue
+		}
+
+		rule, err := sectionRule.Rule()
+		if err != nil {
+			return err
+		}
+
+		statsAPI := newGetFirewallRuleStats(sectionRule.ID)
+		err = nsxclient.Do(statsAPI)
+		if err != nil {
+			return err
+		}
+		err = checkerr(statsAPI)
+		if err != nil {
+			return fmt.Errorf("Error while retrieving the statistics of firewall rule %d: %s", sectionRule.ID, err)
+		}
+
+		stats := statsAPI.GetResponse()
+		rules = append(rules, map[string]interface{}{
+			"id":            sectionRule.ID,
+			"name":          rule.Name,
+			"hit_count":     int(stats.Value.HitCount),
+			"packet_count":  int(stats.Value.PacketCount),
+			"byte_count":    int(stats.Value.ByteCount),
+			"session_count": int(stats.Value.SessionCount),
+			"timestamp":     int(stats.Timestamp),
+		})
+		if stats.Value.HitCount == 0 {
+			unusedRuleIDs = append(unusedRuleIDs, sectionRule.ID)
+		}
+	}
+
+	if ruleFilter && len(rules) == 0 {
+		return fmt.Errorf("Firewall rule %d not found in section %d", ruleID.(int), sectionID)
+	}
+
+	if ruleFilter {
+		d.SetId(fmt.Sprintf("%d/%d", sectionID, ruleID.(int)))
+	} else {
+		d.SetId(fmt.Sprintf("%d", sectionID))
+	}
+	d.Set("unused_rule_ids", unusedRuleIDs)
+	return d.Set("rules", rules)
+}
@@ -22,7 +22,39 @@ func dataSourceSecurityGroup() *schema.Resource {
 			"scopeid": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
-				Default:  "globalroot-0",
+				Default:  globalScopeID,
+			},
+			"virtual_machines": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"moid": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"ip_addresses": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"mac_addresses": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"vnics": &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "UUIDs of the vNICs of the group",
 			},
 		},
 	}
@@ -40,8 +72,74 @@ func dataSourceSecurityGroupRead(d *schema.ResourceData, meta interface{}) error
 	for _, secGroup := range getAllAPI.GetResponse().SecurityGroups {
 		if secGroup.Name == name {
 			d.SetId(secGroup.ObjectID)
-			return nil
+			return readSecurityGroupTranslation(d, nsxclient)
 		}
 	}
 	return fmt.Errorf("Security group %s not found", name)
 }
+
+// getSecurityGroupTranslation fills response with the translation of the
+// group into objects of the given kind.
+func getSecurityGroupTranslation(nsxclient *gonsx.NSXClient, securityGroupID, translation string, response interface{}) error {
+	getAPI := newGetSecurityGroupTranslation(securityGroupID, translation, response)
+	err := nsxclient.Do(getAPI)
+	if err != nil {
+		return err
+	}
+
+	err = checkerr(getAPI)
+	if err != nil {
+		return fmt.Errorf("Error while translating security group %s to %s: %s", securityGroupID, translation, err)
+	}
+	return nil
+}
+
+func readSecurityGroupTranslation(d *schema.ResourceData, nsxclient *gonsx.NSXClient) error {
+	vmNodes := new(securityGroupVMNodes)
+	err := getSecurityGroupTranslation(nsxclient, d.Id(), "virtualmachines", vmNodes)
+	if err != nil {
+		return err
+	}
+	virtualMachines := make([]map[string]interface{}, len(vmNodes.Nodes))
+	for i, node := range vmNodes.Nodes {
+		virtualMachines[i] = map[string]interface{}{
+			"moid": node.ID,
+			"name": node.Name,
+		}
+	}
+
+	ipNodes := new(securityGroupIPNodes)
+	err = getSecurityGroupTranslation(nsxclient, d.Id(), "ipaddresses", ipNodes)
+	if err != nil {
+		return err
+	}
+	ipAddresses := make([]string, 0)
+	for _, node := range ipNodes.Nodes {
+		ipAddresses = append(ipAddresses, node.IPAddresses...)
+	}
+
+	macNodes := new(securityGroupMACNodes)
+	err = getSecurityGroupTranslation(nsxclient, d.Id(), "macaddresses", macNodes)
+	if err != nil {
+		return err
+	}
+	macAddresses := make([]string, 0)
+	for _, node := range macNodes.Nodes {
+		macAddresses = append(macAddresses, node.MACAddresses...)
+	}
+
+	vnicNodes := new(securityGroupVnicNodes)
+	err = getSecurityGroupTranslation(nsxclient, d.Id(), "vnics", vnicNodes)
+	if err != nil {
+		return err
+	}
+	vnics := make([]string, len(vnicNodes.Nodes))
+	for i, node := range vnicNodes.Nodes {
+		vnics[i] = node.UUID
+	}
+
+	d.Set("virtual_machines", virtualMachines)
+	d.Set("ip_addresses", ipAddresses)
+	d.Set("mac_addresses", macAddresses)
+	return d.Set("vnics", vnics)
+}
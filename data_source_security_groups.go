@@ -0,0 +1,134 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+)
+
+func dataSourceSecurityGroups() *schema.Resource {
+
+	return &schema.Resource{
+
+		Read: dataSourceSecurityGroupsRead,
+
+		Schema: map[string]*schema.Schema{
+			"scopeid": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  globalScopeID,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return the groups whose name matches this regular expression",
+			},
+			"description_contains": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the groups whose description contains this string",
+			},
+			"has_dynamic_membership": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return the groups with, or without, a dynamic membership",
+			},
+			"ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"security_groups": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"revision": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// hasDynamicMembership tells if a security group has at least one dynamic
+// membership rule.
+func hasDynamicMembership(group *securityGroup) bool {
+	if group.DynamicMemberDefinition == nil {
+		return false
+	}
+	for _, dynamicSet := range group.DynamicMemberDefinition.DynamicSet {
+		if len(dynamicSet.DynamicCriteria) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func dataSourceSecurityGroupsRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+	scopeID := d.Get("scopeid").(string)
+
+	getAllAPI := newGetAllSecurityGroups(scopeID)
+	err := nsxclient.Do(getAllAPI)
+	if err != nil {
+		return err
+	}
+
+	err = checkerr(getAllAPI)
+	if err != nil {
+		return err
+	}
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+	descriptionContains := d.Get("description_contains").(string)
+	dynamicMembership, filterDynamicMembership := d.GetOkExists("has_dynamic_membership")
+
+	ids := make([]string, 0)
+	groups := make([]map[string]interface{}, 0)
+	for i := range getAllAPI.GetResponse().SecurityGroups {
+		group := &getAllAPI.GetResponse().SecurityGroups[i]
+		if nameRegex != nil && !nameRegex.MatchString(group.Name) {
+			continue
+		}
+		if !strings.Contains(group.Description, descriptionContains) {
+			continue
+		}
+		if filterDynamicMembership && hasDynamicMembership(group) != dynamicMembership.(bool) {
+			continue
+		}
+		ids = append(ids, group.ObjectID)
+		groups = append(groups, map[string]interface{}{
+			"id":          group.ObjectID,
+			"name":        group.Name,
+			"description": group.Description,
+			"revision":    group.Revision,
+		})
+	}
+
+	d.SetId(scopeID)
+	d.Set("ids", ids)
+	return d.Set("security_groups", groups)
+}
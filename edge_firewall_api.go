@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sky-uk/gonsx/api"
+)
+
+// gonsx has no support for the firewall of edges, the objects below cover
+// its rules and default policy.
+
+func edgeFirewallEndpoint(edgeID string) string {
+	return fmt.Sprintf("/api/4.0/edges/%s/firewall/config", edgeID)
+}
+
+// edgeFirewallRules - <firewallRules> element, used to add rules
+type edgeFirewallRules struct {
+	XMLName xml.Name           `xml:"firewallRules"`
+	Rules   []edgeFirewallRule `xml:"firewallRule"`
+}
+
+// edgeFirewallRule - <firewallRule> element
+type edgeFirewallRule struct {
+	XMLName         xml.Name                 `xml:"firewallRule"`
+	ID              string                   `xml:"id,omitempty"`
+	RuleTag         int                      `xml:"ruleTag,omitempty"`
+	Name            string                   `xml:"name"`
+	RuleType        string                   `xml:"ruleType,omitempty"`
+	Source          *edgeFirewallRuleMatch   `xml:"source,omitempty"`
+	Destination     *edgeFirewallRuleMatch   `xml:"destination,omitempty"`
+	Application     *edgeFirewallApplication `xml:"application,omitempty"`
+	MatchTranslated bool                     `xml:"matchTranslated"`
+	Direction       string                   `xml:"direction,omitempty"`
+	Action          string                   `xml:"action"`
+	Enabled         bool                     `xml:"enabled"`
+	LoggingEnabled  bool                     `xml:"loggingEnabled"`
+	Description     string                   `xml:"description,omitempty"`
+}
+
+// edgeFirewallRuleMatch - <source> and <destination> elements of a rule
+type edgeFirewallRuleMatch struct {
+	Exclude           bool     `xml:"exclude"`
+	IPAddresses       []string `xml:"ipAddress,omitempty"`
+	GroupingObjectIDs []string `xml:"groupingObjectId,omitempty"`
+	VnicGroupIDs      []string `xml:"vnicGroupId,omitempty"`
+}
+
+// edgeFirewallApplication - <application> element of a rule
+type edgeFirewallApplication struct {
+	ApplicationIDs []string              `xml:"applicationId,omitempty"`
+	Services       []edgeFirewallService `xml:"service,omitempty"`
+}
+
+// edgeFirewallService - <service> element of <application>
+type edgeFirewallService struct {
+	Protocol   string `xml:"protocol"`
+	Port       string `xml:"port,omitempty"`
+	SourcePort string `xml:"sourcePort,omitempty"`
+	IcmpType   string `xml:"icmpType,omitempty"`
+}
+
+// edgeFirewallDefaultPolicy - <firewallDefaultPolicy> element
+type edgeFirewallDefaultPolicy struct {
+	XMLName        xml.Name `xml:"firewallDefaultPolicy"`
+	Action         string   `xml:"action"`
+	LoggingEnabled bool     `xml:"loggingEnabled"`
+}
+
+// createEdgeFirewallRuleAPI api object
+type createEdgeFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newCreateEdgeFirewallRule returns a new object of createEdgeFirewallRuleAPI.
+// The rule is added above the rule aboveRuleID, or at the bottom of the user
+// rules when it is empty.
+func newCreateEdgeFirewallRule(edgeID, aboveRuleID string, rule edgeFirewallRule) *createEdgeFirewallRuleAPI {
+	this := new(createEdgeFirewallRuleAPI)
+
+	endpoint := edgeFirewallEndpoint(edgeID) + "/rules"
+	if aboveRuleID != "" {
+		query := url.Values{}
+		query.Set("aboveRuleId", aboveRuleID)
+		endpoint += "?" + query.Encode()
+	}
+
+	requestPayload := &edgeFirewallRules{Rules: []edgeFirewallRule{rule}}
+	this.BaseAPI = api.NewBaseAPI(http.MethodPost, endpoint, requestPayload, nil)
+
+	return this
+}
+
+// RuleID returns the ID of the created rule, the last part of the Location
+// header of the response.
+func (ca createEdgeFirewallRuleAPI) RuleID() string {
+	location := ca.ResponseHeaders().Get("Location")
+	return location[strings.LastIndex(location, "/")+1:]
+}
+
+// getEdgeFirewallRuleAPI api object
+type getEdgeFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newGetEdgeFirewallRule returns a new object of getEdgeFirewallRuleAPI.
+func newGetEdgeFirewallRule(edgeID, ruleID string) *getEdgeFirewallRuleAPI {
+	this := new(getEdgeFirewallRuleAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, edgeFirewallEndpoint(edgeID)+"/rules/"+ruleID, nil, new(edgeFirewallRule))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getEdgeFirewallRuleAPI.
+func (ga getEdgeFirewallRuleAPI) GetResponse() *edgeFirewallRule {
+	return ga.ResponseObject().(*edgeFirewallRule)
+}
+
+// updateEdgeFirewallRuleAPI api object
+type updateEdgeFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateEdgeFirewallRule returns a new object of updateEdgeFirewallRuleAPI.
+func newUpdateEdgeFirewallRule(edgeID, ruleID string, rule edgeFirewallRule) *updateEdgeFirewallRuleAPI {
+	this := new(updateEdgeFirewallRuleAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, edgeFirewallEndpoint(edgeID)+"/rules/"+ruleID, rule, nil)
+
+	return this
+}
+
+// deleteEdgeFirewallRuleAPI api object
+type deleteEdgeFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newDeleteEdgeFirewallRule returns a new object of deleteEdgeFirewallRuleAPI.
+func newDeleteEdgeFirewallRule(edgeID, ruleID string) *deleteEdgeFirewallRuleAPI {
+	this := new(deleteEdgeFirewallRuleAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodDelete, edgeFirewallEndpoint(edgeID)+"/rules/"+ruleID, nil, nil)
+
+	return this
+}
+
+// getEdgeFirewallDefaultPolicyAPI api object
+type getEdgeFirewallDefaultPolicyAPI struct {
+	*api.BaseAPI
+}
+
+// newGetEdgeFirewallDefaultPolicy returns a new object of getEdgeFirewallDefaultPolicyAPI.
+func newGetEdgeFirewallDefaultPolicy(edgeID string) *getEdgeFirewallDefaultPolicyAPI {
+	this := new(getEdgeFirewallDefaultPolicyAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, edgeFirewallEndpoint(edgeID)+"/defaultpolicy", nil, new(edgeFirewallDefaultPolicy))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getEdgeFirewallDefaultPolicyAPI.
+func (ga getEdgeFirewallDefaultPolicyAPI) GetResponse() *edgeFirewallDefaultPolicy {
+	return ga.ResponseObject().(*edgeFirewallDefaultPolicy)
+}
+
+// updateEdgeFirewallDefaultPolicyAPI api object
+type updateEdgeFirewallDefaultPolicyAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateEdgeFirewallDefaultPolicy returns a new object of updateEdgeFirewallDefaultPolicyAPI.
+func newUpdateEdgeFirewallDefaultPolicy(edgeID string, policy edgeFirewallDefaultPolicy) *updateEdgeFirewallDefaultPolicyAPI {
+	this := new(updateEdgeFirewallDefaultPolicyAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, edgeFirewallEndpoint(edgeID)+"/defaultpolicy", policy, nil)
+
+	return this
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sky-uk/gonsx"
+	"github.com/sky-uk/gonsx/api"
+)
+
+// firewallMutexKey is the nsxMutexKV key every write to the distributed
+// firewall is serialized with, they all bump the same generation number.
+const firewallMutexKey = "distributed-firewall"
+
+// firewallWriteAttempts bounds the number of times a write is sent when NSX
+// reports an ETag conflict.
+const firewallWriteAttempts = 5
+
+// firewallWriteBackoff is the wait before the first retry, doubled each time.
+const firewallWriteBackoff = 2 * time.Second
+
+// firewallWrite sends a write to the distributed firewall. build is called
+// before every attempt and must fetch a fresh ETag, so that a write rejected
+// because somebody else changed the firewall in between can be retried. build
+// returns a nil api when there is nothing left to write. The other writes of
+// the firewall go on while a rejected one waits for its retry.
+func firewallWrite(nsxclient *gonsx.NSXClient, build func() (api.NSXApi, error)) error {
+	backoff := firewallWriteBackoff
+	for attempt := 1; ; attempt++ {
+		writeAPI, err := firewallWriteAttempt(nsxclient, build)
+		if err != nil || writeAPI == nil {
+			return err
+		}
+
+		if !isEtagConflict(writeAPI.StatusCode()) || attempt == firewallWriteAttempts {
+			return checkerr(writeAPI)
+		}
+
+		log.Printf("[DEBUG] firewall write %s %s rejected with status %d, retrying in %s",
+			writeAPI.Method(), writeAPI.Endpoint(), writeAPI.StatusCode(), backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// firewallWriteAttempt builds and sends a write holding the firewall lock,
+// it returns a nil api when there was nothing to write.
+func firewallWriteAttempt(nsxclient *gonsx.NSXClient, build func() (api.NSXApi, error)) (api.NSXApi, error) {
+	nsxMutexKV.Lock(firewallMutexKey)
+	defer nsxMutexKV.Unlock(firewallMutexKey)
+
+	writeAPI, err := build()
+	if err != nil || writeAPI == nil {
+		return nil, err
+	}
+	return writeAPI, nsxclient.Do(writeAPI)
+}
+
+func isEtagConflict(statusCode int) bool {
+	return statusCode == http.StatusPreconditionFailed || statusCode == http.StatusConflict
+}
+
+// firewallPublishTimeout is the default time given to NSX to publish the
+// firewall configuration to every cluster.
+const firewallPublishTimeout = 10 * time.Minute
+
+func schemaWaitForPublish() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Wait for the firewall configuration to be published to every cluster",
+	}
+}
+
+// firewallResourceTimeouts are the timeouts of the resources that can wait for
+// the firewall to be published.
+func firewallResourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(firewallPublishTimeout),
+		Update: schema.DefaultTimeout(firewallPublishTimeout),
+		Delete: schema.DefaultTimeout(firewallPublishTimeout),
+	}
+}
+
+// waitForFirewallPublish waits for the last change of the firewall to reach
+// every cluster when the resource asks for it.
+func waitForFirewallPublish(d *schema.ResourceData, nsxclient *gonsx.NSXClient, timeout time.Duration) error {
+	if !d.Get("wait_for_publish").(bool) {
+		return nil
+	}
+
+	// Whether the firewall is enabled on each cluster, looked up once.
+	enabled := make(map[string]bool)
+
+	return resource.Retry(timeout, func() *resource.RetryError {
+		statusAPI := newGetFirewallStatus()
+		err := nsxclient.Do(statusAPI)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		err = checkerr(statusAPI)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		status := statusAPI.GetResponse()
+		for _, cluster := range status.Clusters {
+			if _, ok := enabled[cluster.ID]; ok {
+				continue
+			}
+			enabled[cluster.ID], err = isFirewallEnabled(nsxclient, cluster.ID)
+			if err != nil {
+				return resource.NonRetryableError(err)
+			}
+		}
+
+		err = firewallPublished(status, enabled)
+		if err != nil {
+			log.Printf("[DEBUG] %s", err)
+			return resource.RetryableError(err)
+		}
+		return nil
+	})
+}
+
+// firewallPublished returns an error naming the lagging clusters until every
+// cluster the firewall is enabled on runs the current generation of the
+// firewall configuration. Clusters without the firewall never catch up and
+// are skipped.
+func firewallPublished(status *firewallStatus, enabled map[string]bool) error {
+	if status.Status != "published" {
+		return fmt.Errorf("firewall generation %s is %s", status.GenerationNumber, status.Status)
+	}
+
+	var lagging []string
+	for _, cluster := range status.Clusters {
+		if !enabled[cluster.ID] || cluster.GenerationNumber == status.GenerationNumber {
+			continue
+		}
+		lagging = append(lagging, fmt.Sprintf("%s (%s) runs generation %s", cluster.Name, cluster.ID, cluster.GenerationNumber))
+	}
+	if len(lagging) > 0 {
+		return fmt.Errorf("firewall generation %s not published to every cluster yet: %s",
+			status.GenerationNumber, strings.Join(lagging, ", "))
+	}
+	return nil
+}
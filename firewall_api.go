@@ -0,0 +1,665 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sky-uk/gonsx/api"
+	"github.com/sky-uk/gonsx/api/firewall"
+)
+
+// gonsx only knows about the rules of layer3 sections, the objects below
+// cover sections and rules of every type used by this provider.
+
+const firewallConfigEndpoint = "/api/4.0/firewall/globalroot-0/config"
+
+// firewallSectionPaths maps a section type to its path in the firewall API.
+var firewallSectionPaths = map[string]string{
+	"LAYER3":     "layer3sections",
+	"LAYER2":     "layer2sections",
+	"L3REDIRECT": "layer3redirectsections",
+}
+
+func firewallSectionEndpoint(sectionType string, sectionID int) string {
+	return fmt.Sprintf("%s/%s/%d", firewallConfigEndpoint, firewallSectionPaths[sectionType], sectionID)
+}
+
+// firewallSection - <section> element of the firewall configuration
+type firewallSection struct {
+	XMLName          xml.Name              `xml:"section"`
+	ID               int                   `xml:"id,attr,omitempty"`
+	Name             string                `xml:"name,attr"`
+	GenerationNumber string                `xml:"generationNumber,attr,omitempty"`
+	Timestamp        string                `xml:"timestamp,attr,omitempty"`
+	Type             string                `xml:"type,attr,omitempty"`
+	Stateless        bool                  `xml:"stateless,attr"`
+	ManagedBy        string                `xml:"managedBy,attr,omitempty"`
+	Attrs            []xml.Attr            `xml:",any,attr"`
+	Rules            []firewallSectionRule `xml:"rule"`
+}
+
+// firewallRule - <rule> element, firewall.Rule with services that can be
+// defined inline. Services shadows the field of the embedded rule.
+type firewallRule struct {
+	firewall.Rule
+	Services *firewallServices `xml:"services,omitempty"`
+}
+
+// firewallServices - <services> element of <rule>
+type firewallServices struct {
+	Elements []firewallService `xml:"service,omitempty"`
+}
+
+// firewallService - <service> element of <services>, either a reference to
+// a service object or an inline protocol and ports definition.
+type firewallService struct {
+	Name            string            `xml:"name,omitempty"`
+	Value           string            `xml:"value,omitempty"`
+	Type            firewall.ElemType `xml:"type,omitempty"`
+	IsValid         bool              `xml:"isValid,omitempty"`
+	SourcePort      string            `xml:"sourcePort,omitempty"`
+	DestinationPort string            `xml:"destinationPort,omitempty"`
+	Protocol        int               `xml:"protocol,omitempty"`
+	ProtocolName    string            `xml:"protocolName,omitempty"`
+	SubProtocolName string            `xml:"subProtocolName,omitempty"`
+}
+
+// firewallSectionRule - <rule> element of <section>. The rule is kept as raw
+// xml so that sending a section back never drops what gonsx doesn't know.
+type firewallSectionRule struct {
+	XMLName xml.Name   `xml:"rule"`
+	ID      int        `xml:"id,attr,omitempty"`
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   []byte     `xml:",innerxml"`
+}
+
+// newFirewallSectionRule converts a rule to its raw section form.
+func newFirewallSectionRule(rule *firewallRule) (firewallSectionRule, error) {
+	var sectionRule firewallSectionRule
+
+	raw, err := xml.Marshal(rule)
+	if err != nil {
+		return sectionRule, err
+	}
+	err = xml.Unmarshal(raw, &sectionRule)
+	return sectionRule, err
+}
+
+// Rule decodes the raw section rule.
+func (r firewallSectionRule) Rule() (*firewallRule, error) {
+	raw, err := xml.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := new(firewallRule)
+	err = xml.Unmarshal(raw, rule)
+	return rule, err
+}
+
+// createFirewallSectionAPI api object
+type createFirewallSectionAPI struct {
+	*api.BaseAPI
+}
+
+// newCreateFirewallSection returns a new object of createFirewallSectionAPI.
+// operation is one of insert_top, insert_bottom, insert_before or
+// insert_after, anchorID is only used by the last two.
+func newCreateFirewallSection(etag, operation string, anchorID int, section *firewallSection) *createFirewallSectionAPI {
+	this := new(createFirewallSectionAPI)
+
+	query := url.Values{}
+	query.Set("operation", operation)
+	if anchorID != 0 {
+		query.Set("anchorId", fmt.Sprintf("%d", anchorID))
+	}
+
+	this.BaseAPI = api.NewBaseAPI(http.MethodPost,
+		fmt.Sprintf("%s/%s?%s", firewallConfigEndpoint, firewallSectionPaths[section.Type], query.Encode()),
+		section, new(firewallSection))
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of createFirewallSectionAPI.
+func (ca createFirewallSectionAPI) GetResponse() *firewallSection {
+	return ca.ResponseObject().(*firewallSection)
+}
+
+// getFirewallSectionAPI api object
+type getFirewallSectionAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallSection returns a new object of getFirewallSectionAPI.
+func newGetFirewallSection(sectionType string, sectionID int) *getFirewallSectionAPI {
+	this := new(getFirewallSectionAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, firewallSectionEndpoint(sectionType, sectionID), nil, new(firewallSection))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallSectionAPI.
+func (ga getFirewallSectionAPI) GetResponse() *firewallSection {
+	return ga.ResponseObject().(*firewallSection)
+}
+
+// updateFirewallSectionAPI api object
+type updateFirewallSectionAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateFirewallSection returns a new object of updateFirewallSectionAPI.
+// The rules of the section are replaced by the ones of the payload.
+func newUpdateFirewallSection(etag string, section *firewallSection) *updateFirewallSectionAPI {
+	this := new(updateFirewallSectionAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, firewallSectionEndpoint(section.Type, section.ID), section, new(firewallSection))
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of updateFirewallSectionAPI.
+func (ua updateFirewallSectionAPI) GetResponse() *firewallSection {
+	return ua.ResponseObject().(*firewallSection)
+}
+
+// reviseFirewallSectionAPI api object
+type reviseFirewallSectionAPI struct {
+	*api.BaseAPI
+}
+
+// newReviseFirewallSection returns a new object of reviseFirewallSectionAPI,
+// it moves an existing section to a new position.
+func newReviseFirewallSection(etag, operation string, anchorID int, section *firewallSection) *reviseFirewallSectionAPI {
+	this := new(reviseFirewallSectionAPI)
+
+	query := url.Values{}
+	query.Set("action", "revise")
+	query.Set("operation", operation)
+	if anchorID != 0 {
+		query.Set("anchorId", fmt.Sprintf("%d", anchorID))
+	}
+
+	this.BaseAPI = api.NewBaseAPI(http.MethodPost,
+		fmt.Sprintf("%s?%s", firewallSectionEndpoint(section.Type, section.ID), query.Encode()),
+		section, new(firewallSection))
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of reviseFirewallSectionAPI.
+func (ra reviseFirewallSectionAPI) GetResponse() *firewallSection {
+	return ra.ResponseObject().(*firewallSection)
+}
+
+// deleteFirewallSectionAPI api object
+type deleteFirewallSectionAPI struct {
+	*api.BaseAPI
+}
+
+// newDeleteFirewallSection returns a new object of deleteFirewallSectionAPI.
+func newDeleteFirewallSection(etag, sectionType string, sectionID int) *deleteFirewallSectionAPI {
+	this := new(deleteFirewallSectionAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodDelete, firewallSectionEndpoint(sectionType, sectionID), nil, nil)
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+func firewallRuleEndpoint(sectionType string, sectionID, ruleID int) string {
+	return fmt.Sprintf("%s/rules/%d", firewallSectionEndpoint(sectionType, sectionID), ruleID)
+}
+
+// setFirewallRuleDefaults applies the defaults of firewall.NewCreateRule.
+func setFirewallRuleDefaults(rule *firewallRule) {
+	if rule.PacketType == "" {
+		rule.PacketType = "any"
+	}
+	if rule.Action == "" {
+		rule.Action = firewall.Allow
+	}
+	if rule.AppliedToList == nil || len(rule.AppliedToList.Elements) == 0 {
+		rule.AppliedToList = &firewall.AppliedToList{
+			Elements: []firewall.Element{
+				{
+					Type:  firewall.DISTRIBUTED_FIREWALL,
+					Value: string(firewall.DISTRIBUTED_FIREWALL),
+				},
+			},
+		}
+	}
+}
+
+// createFirewallRuleAPI api object
+type createFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newCreateFirewallRule returns a new object of createFirewallRuleAPI.
+func newCreateFirewallRule(sectionType string, sectionID int, etag string, rule *firewallRule) *createFirewallRuleAPI {
+	this := new(createFirewallRuleAPI)
+	rule.SectionId = sectionID
+	setFirewallRuleDefaults(rule)
+
+	this.BaseAPI = api.NewBaseAPI(http.MethodPost, firewallSectionEndpoint(sectionType, sectionID)+"/rules", rule, new(firewallRule))
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of createFirewallRuleAPI.
+func (ca createFirewallRuleAPI) GetResponse() *firewallRule {
+	return ca.ResponseObject().(*firewallRule)
+}
+
+// getFirewallRuleAPI api object
+type getFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallRule returns a new object of getFirewallRuleAPI.
+func newGetFirewallRule(sectionType string, sectionID, ruleID int) *getFirewallRuleAPI {
+	this := new(getFirewallRuleAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, firewallRuleEndpoint(sectionType, sectionID, ruleID), nil, new(firewallRule))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallRuleAPI.
+func (ga getFirewallRuleAPI) GetResponse() *firewallRule {
+	return ga.ResponseObject().(*firewallRule)
+}
+
+// updateFirewallRuleAPI api object
+type updateFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateFirewallRule returns a new object of updateFirewallRuleAPI.
+func newUpdateFirewallRule(sectionType string, sectionID int, etag string, ruleID int, rule firewallRule) *updateFirewallRuleAPI {
+	this := new(updateFirewallRuleAPI)
+	rule.SectionId = sectionID
+	rule.ID = ruleID
+	setFirewallRuleDefaults(&rule)
+
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, firewallRuleEndpoint(sectionType, sectionID, ruleID), rule, new(firewallRule))
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of updateFirewallRuleAPI.
+func (ua updateFirewallRuleAPI) GetResponse() *firewallRule {
+	return ua.ResponseObject().(*firewallRule)
+}
+
+// deleteFirewallRuleAPI api object
+type deleteFirewallRuleAPI struct {
+	*api.BaseAPI
+}
+
+// newDeleteFirewallRule returns a new object of deleteFirewallRuleAPI.
+func newDeleteFirewallRule(sectionType string, sectionID int, etag string, ruleID int) *deleteFirewallRuleAPI {
+	this := new(deleteFirewallRuleAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodDelete, firewallRuleEndpoint(sectionType, sectionID, ruleID), nil, nil)
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+// firewallStatus - publish status of the distributed firewall configuration
+type firewallStatus struct {
+	XMLName          xml.Name                `xml:"firewallStatus"`
+	Status           string                  `xml:"status"`
+	GenerationNumber string                  `xml:"generationNumber"`
+	Clusters         []firewallClusterStatus `xml:"clusterList>clusterStatus"`
+}
+
+// firewallClusterStatus - publish status of a cluster
+type firewallClusterStatus struct {
+	ID               string `xml:"id"`
+	Name             string `xml:"name"`
+	Status           string `xml:"status"`
+	GenerationNumber string `xml:"generationNumber"`
+}
+
+// getFirewallStatusAPI api object
+type getFirewallStatusAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallStatus returns a new object of getFirewallStatusAPI.
+func newGetFirewallStatus() *getFirewallStatusAPI {
+	this := new(getFirewallStatusAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, "/api/4.0/firewall/globalroot-0/status", nil, new(firewallStatus))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallStatusAPI.
+func (ga getFirewallStatusAPI) GetResponse() *firewallStatus {
+	return ga.ResponseObject().(*firewallStatus)
+}
+
+// firewallRuleStats - statistics of a rule since its creation
+type firewallRuleStats struct {
+	Timestamp int64                  `xml:"timestamp"`
+	Value     firewallRuleStatsValue `xml:"value"`
+}
+
+// firewallRuleStatsValue - <value> element of the rule statistics
+type firewallRuleStatsValue struct {
+	HitCount     int64 `xml:"hitCount"`
+	PacketCount  int64 `xml:"packetCount"`
+	ByteCount    int64 `xml:"byteCount"`
+	SessionCount int64 `xml:"sessionCount"`
+}
+
+// getFirewallRuleStatsAPI api object
+type getFirewallRuleStatsAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallRuleStats returns a new object of getFirewallRuleStatsAPI.
+func newGetFirewallRuleStats(ruleID int) *getFirewallRuleStatsAPI {
+	this := new(getFirewallRuleStatsAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, fmt.Sprintf("/api/4.0/firewall/stats/rules/%d", ruleID), nil, new(firewallRuleStats))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallRuleStatsAPI.
+func (ga getFirewallRuleStatsAPI) GetResponse() *firewallRuleStats {
+	return ga.ResponseObject().(*firewallRuleStats)
+}
+
+// firewallGlobalConfiguration - global options of the distributed firewall.
+// The options are kept as raw xml, in order, so that sending the
+// configuration back never resets what the provider doesn't know.
+type firewallGlobalConfiguration struct {
+	XMLName xml.Name               `xml:"globalConfiguration"`
+	Options []firewallGlobalOption `xml:",any"`
+}
+
+// firewallGlobalOption - option element of <globalConfiguration>
+type firewallGlobalOption struct {
+	XMLName xml.Name
+	Value   string `xml:",innerxml"`
+}
+
+// Bool returns the value of a boolean option, false when it is not set.
+func (c *firewallGlobalConfiguration) Bool(name string) bool {
+	for _, option := range c.Options {
+		if option.XMLName.Local == name {
+			value, _ := strconv.ParseBool(strings.TrimSpace(option.Value))
+			return value
+		}
+	}
+	return false
+}
+
+// SetBool sets the value of a boolean option, adding it when it is not set.
+func (c *firewallGlobalConfiguration) SetBool(name string, value bool) {
+	for i := range c.Options {
+		if c.Options[i].XMLName.Local == name {
+			c.Options[i].Value = strconv.FormatBool(value)
+			return
+		}
+	}
+	c.Options = append(c.Options, firewallGlobalOption{
+		XMLName: xml.Name{Local: name},
+		Value:   strconv.FormatBool(value),
+	})
+}
+
+const firewallGlobalConfigurationEndpoint = "/api/4.0/firewall/config/globalconfiguration"
+
+// getFirewallGlobalConfigurationAPI api object
+type getFirewallGlobalConfigurationAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallGlobalConfiguration returns a new object of getFirewallGlobalConfigurationAPI.
+func newGetFirewallGlobalConfiguration() *getFirewallGlobalConfigurationAPI {
+	this := new(getFirewallGlobalConfigurationAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, firewallGlobalConfigurationEndpoint, nil, new(firewallGlobalConfiguration))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallGlobalConfigurationAPI.
+func (ga getFirewallGlobalConfigurationAPI) GetResponse() *firewallGlobalConfiguration {
+	return ga.ResponseObject().(*firewallGlobalConfiguration)
+}
+
+// updateFirewallGlobalConfigurationAPI api object
+type updateFirewallGlobalConfigurationAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateFirewallGlobalConfiguration returns a new object of updateFirewallGlobalConfigurationAPI.
+func newUpdateFirewallGlobalConfiguration(config *firewallGlobalConfiguration) *updateFirewallGlobalConfigurationAPI {
+	this := new(updateFirewallGlobalConfigurationAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, firewallGlobalConfigurationEndpoint, config, nil)
+
+	return this
+}
+
+// enableFirewallAPI api object
+type enableFirewallAPI struct {
+	*api.BaseAPI
+}
+
+// newEnableFirewall returns a new object of enableFirewallAPI, it enables or
+// disables the distributed firewall on a cluster.
+func newEnableFirewall(clusterID string, enabled bool) *enableFirewallAPI {
+	this := new(enableFirewallAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPost, fmt.Sprintf("/api/4.0/firewall/%s/enable/%t", clusterID, enabled), nil, nil)
+
+	return this
+}
+
+// firewallFeatureID is the network fabric feature of the distributed firewall.
+const firewallFeatureID = "com.vmware.vshield.firewall"
+
+// fabricResourceStatuses - network fabric status of a resource
+type fabricResourceStatuses struct {
+	Statuses []fabricResourceStatus `xml:"resourceStatus"`
+}
+
+// fabricResourceStatus - <resourceStatus> element
+type fabricResourceStatus struct {
+	Resource string                `xml:"resource>objectId"`
+	Features []fabricFeatureStatus `xml:"nwFabricFeatureStatus"`
+}
+
+// fabricFeatureStatus - <nwFabricFeatureStatus> element
+type fabricFeatureStatus struct {
+	FeatureID string `xml:"featureId"`
+	Installed bool   `xml:"installed"`
+	Enabled   bool   `xml:"enabled"`
+	Status    string `xml:"status"`
+}
+
+// getFabricStatusAPI api object
+type getFabricStatusAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFabricStatus returns a new object of getFabricStatusAPI.
+func newGetFabricStatus(resourceID string) *getFabricStatusAPI {
+	this := new(getFabricStatusAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, "/api/2.0/nwfabric/status?resource="+url.QueryEscape(resourceID), nil, new(fabricResourceStatuses))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFabricStatusAPI.
+func (ga getFabricStatusAPI) GetResponse() *fabricResourceStatuses {
+	return ga.ResponseObject().(*fabricResourceStatuses)
+}
+
+// firewallConfiguration - the whole distributed firewall configuration, kept
+// as raw xml.
+type firewallConfiguration struct {
+	XMLName xml.Name   `xml:"firewallConfiguration"`
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   []byte     `xml:",innerxml"`
+}
+
+// getFirewallConfigurationAPI api object
+type getFirewallConfigurationAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallConfiguration returns a new object of
+// getFirewallConfigurationAPI, the raw counterpart of
+// firewall.NewGetFirewallConfig.
+func newGetFirewallConfiguration() *getFirewallConfigurationAPI {
+	this := new(getFirewallConfigurationAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, firewallConfigEndpoint, nil, new(firewallConfiguration))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallConfigurationAPI.
+func (ga getFirewallConfigurationAPI) GetResponse() *firewallConfiguration {
+	return ga.ResponseObject().(*firewallConfiguration)
+}
+
+// updateFirewallConfigurationAPI api object
+type updateFirewallConfigurationAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateFirewallConfiguration returns a new object of
+// updateFirewallConfigurationAPI, it replaces the whole configuration.
+func newUpdateFirewallConfiguration(etag string, config *firewallConfiguration) *updateFirewallConfigurationAPI {
+	this := new(updateFirewallConfigurationAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, firewallConfigEndpoint, config, nil)
+	this.SetRequestHeader("If-Match", etag)
+
+	return this
+}
+
+const firewallDraftsEndpoint = "/api/4.0/firewall/globalroot-0/drafts"
+
+// firewallDraft - saved copy of the firewall configuration
+type firewallDraft struct {
+	XMLName     xml.Name             `xml:"firewallDraft"`
+	ID          string               `xml:"id,attr,omitempty"`
+	Name        string               `xml:"name,attr"`
+	Timestamp   int64                `xml:"timestamp,attr,omitempty"`
+	Description string               `xml:"description,omitempty"`
+	Preserve    bool                 `xml:"preserve"`
+	User        string               `xml:"user,omitempty"`
+	Mode        string               `xml:"mode,omitempty"`
+	Config      *firewallDraftConfig `xml:"config,omitempty"`
+}
+
+// firewallDraftConfig - <config> element of a draft, the content of
+// <firewallConfiguration>
+type firewallDraftConfig struct {
+	Inner []byte `xml:",innerxml"`
+}
+
+// createFirewallDraftAPI api object
+type createFirewallDraftAPI struct {
+	*api.BaseAPI
+}
+
+// newCreateFirewallDraft returns a new object of createFirewallDraftAPI.
+func newCreateFirewallDraft(draft *firewallDraft) *createFirewallDraftAPI {
+	this := new(createFirewallDraftAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPost, firewallDraftsEndpoint, draft, new(firewallDraft))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of createFirewallDraftAPI.
+func (ca createFirewallDraftAPI) GetResponse() *firewallDraft {
+	return ca.ResponseObject().(*firewallDraft)
+}
+
+// getFirewallDraftAPI api object
+type getFirewallDraftAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallDraft returns a new object of getFirewallDraftAPI.
+func newGetFirewallDraft(draftID string) *getFirewallDraftAPI {
+	this := new(getFirewallDraftAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, firewallDraftsEndpoint+"/"+draftID, nil, new(firewallDraft))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallDraftAPI.
+func (ga getFirewallDraftAPI) GetResponse() *firewallDraft {
+	return ga.ResponseObject().(*firewallDraft)
+}
+
+// updateFirewallDraftAPI api object
+type updateFirewallDraftAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateFirewallDraft returns a new object of updateFirewallDraftAPI.
+func newUpdateFirewallDraft(draft *firewallDraft) *updateFirewallDraftAPI {
+	this := new(updateFirewallDraftAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, firewallDraftsEndpoint+"/"+draft.ID, draft, new(firewallDraft))
+
+	return this
+}
+
+// deleteFirewallDraftAPI api object
+type deleteFirewallDraftAPI struct {
+	*api.BaseAPI
+}
+
+// newDeleteFirewallDraft returns a new object of deleteFirewallDraftAPI.
+func newDeleteFirewallDraft(draftID string) *deleteFirewallDraftAPI {
+	this := new(deleteFirewallDraftAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodDelete, firewallDraftsEndpoint+"/"+draftID, nil, nil)
+
+	return this
+}
+
+// firewallExclusions - the exclusion list, firewallexclusion.FirewallExclusions
+// with the type of its members.
+type firewallExclusions struct {
+	XMLName xml.Name                  `xml:"VshieldAppConfiguration"`
+	Members []firewallExclusionMember `xml:"excludeListConfiguration>excludeMember>member"`
+}
+
+// firewallExclusionMember - <member> element of the exclusion list
+type firewallExclusionMember struct {
+	MOID       string `xml:"objectId"`
+	Name       string `xml:"name"`
+	ObjectType string `xml:"objectTypeName"`
+}
+
+// getFirewallExclusionsAPI api object
+type getFirewallExclusionsAPI struct {
+	*api.BaseAPI
+}
+
+// newGetFirewallExclusions returns a new object of getFirewallExclusionsAPI.
+func newGetFirewallExclusions() *getFirewallExclusionsAPI {
+	this := new(getFirewallExclusionsAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, "/api/2.1/app/excludelist", nil, new(firewallExclusions))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getFirewallExclusionsAPI.
+func (ga getFirewallExclusionsAPI) GetResponse() *firewallExclusions {
+	return ga.ResponseObject().(*firewallExclusions)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestFirewallGlobalConfigurationKeepsUnknownOptions(t *testing.T) {
+	body := `<globalConfiguration>` +
+		`<layer3RuleOptimize>false</layer3RuleOptimize>` +
+		`<layer2RuleOptimize>true</layer2RuleOptimize>` +
+		`<tcpStrictOption>false</tcpStrictOption>` +
+		`<enableSynFloodProtection>true</enableSynFloodProtection>` +
+		`<ruleImportTimeout>600</ruleImportTimeout>` +
+		`</globalConfiguration>`
+
+	config := new(firewallGlobalConfiguration)
+	if err := xml.Unmarshal([]byte(body), config); err != nil {
+		t.Fatal(err)
+	}
+
+	if !config.Bool("layer2RuleOptimize") || config.Bool("layer3RuleOptimize") {
+		t.Errorf("options misread: %+v", config.Options)
+	}
+
+	config.SetBool("tcpStrictOption", true)
+	config.SetBool("autoDraftDisabled", true)
+
+	updated, err := xml.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<globalConfiguration>` +
+		`<layer3RuleOptimize>false</layer3RuleOptimize>` +
+		`<layer2RuleOptimize>true</layer2RuleOptimize>` +
+		`<tcpStrictOption>true</tcpStrictOption>` +
+		`<enableSynFloodProtection>true</enableSynFloodProtection>` +
+		`<ruleImportTimeout>600</ruleImportTimeout>` +
+		`<autoDraftDisabled>true</autoDraftDisabled>` +
+		`</globalConfiguration>`
+	if string(updated) != expected {
+		t.Errorf("got\n%s\nexpected\n%s", updated, expected)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFirewallPublished(t *testing.T) {
+	clusters := func(generations ...string) []firewallClusterStatus {
+		statuses := make([]firewallClusterStatus, len(generations))
+		for i, generation := range generations {
+			statuses[i] = firewallClusterStatus{
+				ID:               []string{"domain-c7", "domain-c8", "domain-c9"}[i],
+				Name:             []string{"compute-a", "compute-b", "edge"}[i],
+				GenerationNumber: generation,
+			}
+		}
+		return statuses
+	}
+	allEnabled := map[string]bool{"domain-c7": true, "domain-c8": true, "domain-c9": true}
+
+	cases := []struct {
+		description string
+		status      firewallStatus
+		enabled     map[string]bool
+		lagging     []string
+	}{
+		{
+			"every cluster is published",
+			firewallStatus{Status: "published", GenerationNumber: "5", Clusters: clusters("5", "5", "5")},
+			allEnabled,
+			nil,
+		},
+		{
+			"the lagging clusters are named",
+			firewallStatus{Status: "published", GenerationNumber: "5", Clusters: clusters("5", "4", "3")},
+			allEnabled,
+			[]string{"compute-b (domain-c8)", "edge (domain-c9)"},
+		},
+		{
+			"clusters without the firewall are skipped",
+			firewallStatus{Status: "published", GenerationNumber: "5", Clusters: clusters("5", "5", "")},
+			map[string]bool{"domain-c7": true, "domain-c8": true},
+			nil,
+		},
+		{
+			"the publication is still in progress",
+			firewallStatus{Status: "inprogress", GenerationNumber: "5", Clusters: clusters("4")},
+			allEnabled,
+			[]string{"inprogress"},
+		},
+	}
+
+	for _, c := range cases {
+		err := firewallPublished(&c.status, c.enabled)
+		if c.lagging == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", c.description, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", c.description)
+			continue
+		}
+		for _, lagging := range c.lagging {
+			if !strings.Contains(err.Error(), lagging) {
+				t.Errorf("%s: %q missing from the error %q", c.description, lagging, err)
+			}
+		}
+	}
+}
@@ -41,21 +41,31 @@ func Provider() terraform.ResourceProvider {
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"nsx_logical_switch":          resourceLogicalSwitch(),
-			"nsx_edge_interface":          resourceEdgeInterface(),
-			"nsx_dhcp_relay":              resourceDHCPRelay(),
-			"nsx_service":                 resourceService(),
-			"nsx_security_group":          resourceSecurityGroup(),
-			"nsx_security_tag":            resourceSecurityTag(),
-			"nsx_security_tag_attachment": resourceSecurityTagAttachment(),
-			"nsx_security_policy":         resourceSecurityPolicy(),
-			"nsx_security_policy_rule":    resourceSecurityPolicyRule(),
-			"nsx_firewall_exclusion":      resourceFirewallExclusion(),
-			"nsx_firewall_rule":           resourceFirewallRule(),
+			"nsx_logical_switch":               resourceLogicalSwitch(),
+			"nsx_edge_interface":               resourceEdgeInterface(),
+			"nsx_dhcp_relay":                   resourceDHCPRelay(),
+			"nsx_service":                      resourceService(),
+			"nsx_security_group":               resourceSecurityGroup(),
+			"nsx_security_tag":                 resourceSecurityTag(),
+			"nsx_security_tag_attachment":      resourceSecurityTagAttachment(),
+			"nsx_security_policy":              resourceSecurityPolicy(),
+			"nsx_security_policy_rule":         resourceSecurityPolicyRule(),
+			"nsx_firewall_exclusion":           resourceFirewallExclusion(),
+			"nsx_firewall_rule":                resourceFirewallRule(),
+			"nsx_firewall_section":             resourceFirewallSection(),
+			"nsx_firewall_section_rules":       resourceFirewallSectionRules(),
+			"nsx_edge_firewall_rule":           resourceEdgeFirewallRule(),
+			"nsx_edge_firewall_default_policy": resourceEdgeFirewallDefaultPolicy(),
+			"nsx_firewall_global_config":       resourceFirewallGlobalConfig(),
+			"nsx_firewall_draft":               resourceFirewallDraft(),
+			"nsx_firewall_exclusion_list":      resourceFirewallExclusionList(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"nsx_security_group": dataSourceSecurityGroup(),
+			"nsx_security_group":      dataSourceSecurityGroup(),
+			"nsx_firewall_rule_stats": dataSourceFirewallRuleStats(),
+			"nsx_firewall_exclusions": dataSourceFirewallExclusions(),
+			"nsx_security_groups":     dataSourceSecurityGroups(),
 		},
 
 		ConfigureFunc: providerConfigure,
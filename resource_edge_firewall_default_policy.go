@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+)
+
+// edgeFirewallDefaultAction is the action of the default policy of a new
+// edge, restored when the resource is destroyed.
+const edgeFirewallDefaultAction = "deny"
+
+func resourceEdgeFirewallDefaultPolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceEdgeFirewallDefaultPolicyUpdate,
+		Read:   resourceEdgeFirewallDefaultPolicyRead,
+		Update: resourceEdgeFirewallDefaultPolicyUpdate,
+		Delete: resourceEdgeFirewallDefaultPolicyDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edgeid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"action": {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"accept",
+					"deny",
+					"reject",
+				}, false),
+			},
+			"logging_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func putEdgeFirewallDefaultPolicy(nsxclient *gonsx.NSXClient, edgeid string, policy edgeFirewallDefaultPolicy) error {
+	nsxMutexKV.Lock(edgeid)
+	defer nsxMutexKV.Unlock(edgeid)
+
+	updateAPI := newUpdateEdgeFirewallDefaultPolicy(edgeid, policy)
+	err := nsxclient.Do(updateAPI)
+	if err != nil {
+		return err
+	}
+
+	err = checkerr(updateAPI)
+	if err != nil {
+		return fmt.Errorf("Error updating the firewall default policy of %s: %s", edgeid, err)
+	}
+	return nil
+}
+
+func resourceEdgeFirewallDefaultPolicyRead(d *schema.ResourceData, m interface{}) error {
+	nsxclient := m.(*gonsx.NSXClient)
+
+	getAPI := newGetEdgeFirewallDefaultPolicy(d.Id())
+	err := nsxclient.Do(getAPI)
+	if err != nil {
+		return err
+	}
+
+	// If the edge has been removed manually, notify Terraform of this fact.
+	if getAPI.StatusCode() == http.StatusNotFound {
+		log.Printf("[DEBUG] edge %s not found, removing its firewall default policy from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	err = checkerr(getAPI)
+	if err != nil {
+		return err
+	}
+
+	policy := getAPI.GetResponse()
+	d.Set("edgeid", d.Id())
+	d.Set("action", policy.Action)
+	d.Set("logging_enabled", policy.LoggingEnabled)
+	return nil
+}
+
+func resourceEdgeFirewallDefaultPolicyUpdate(d *schema.ResourceData, m interface{}) error {
+	nsxclient := m.(*gonsx.NSXClient)
+	edgeid := d.Get("edgeid").(string)
+
+	err := putEdgeFirewallDefaultPolicy(nsxclient, edgeid, edgeFirewallDefaultPolicy{
+		Action:         d.Get("action").(string),
+		LoggingEnabled: d.Get("logging_enabled").(bool),
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(edgeid)
+	return resourceEdgeFirewallDefaultPolicyRead(d, m)
+}
+
+func resourceEdgeFirewallDefaultPolicyDelete(d *schema.ResourceData, m interface{}) error {
+	nsxclient := m.(*gonsx.NSXClient)
+	edgeid := d.Get("edgeid").(string)
+
+	nsxMutexKV.Lock(edgeid)
+	defer nsxMutexKV.Unlock(edgeid)
+
+	// The default policy can't be removed, put back the one of a new edge.
+	updateAPI := newUpdateEdgeFirewallDefaultPolicy(edgeid, edgeFirewallDefaultPolicy{
+		Action: edgeFirewallDefaultAction,
+	})
+	err := nsxclient.Do(updateAPI)
+	if err != nil {
+		return err
+	}
+
+	// The policy of an edge that is already gone went with it.
+	if updateAPI.StatusCode() != http.StatusNotFound {
+		err = checkerr(updateAPI)
+		if err != nil {
+			return fmt.Errorf("Error resetting the firewall default policy of %s: %s", edgeid, err)
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
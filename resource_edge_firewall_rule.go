@@ -0,0 +1,343 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+)
+
+func resourceEdgeFirewallRule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceEdgeFirewallRuleCreate,
+		Read:   resourceEdgeFirewallRuleRead,
+		Update: resourceEdgeFirewallRuleUpdate,
+		Delete: resourceEdgeFirewallRuleDelete,
+
+		Schema: map[string]*schema.Schema{
+			"edgeid": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"action": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "accept",
+				ValidateFunc: validation.StringInSlice([]string{
+					"accept",
+					"deny",
+					"reject",
+				}, false),
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"logging_enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"direction": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Direction of the traffic matched by the rule, both when empty",
+				ValidateFunc: validation.StringInSlice([]string{
+					"in",
+					"out",
+				}, false),
+			},
+			"match_translated": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"source":      schemaEdgeFirewallRuleMatch(),
+			"destination": schemaEdgeFirewallRuleMatch(),
+			"application_ids": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"service": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"protocol": {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								"tcp",
+								"udp",
+								"icmp",
+								"any",
+							}, false),
+						},
+						"port": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"source_port": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"icmp_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"insert_above": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "ID of the rule to add this rule above, defaults to the bottom of the user rules",
+			},
+			"rule_tag": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// schemaEdgeFirewallRuleMatch is the schema of the source and destination of
+// a rule, any when not set.
+func schemaEdgeFirewallRuleMatch() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"exclude": {
+					Type:     schema.TypeBool,
+					Optional: true,
+				},
+				"ip_addresses": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"grouping_object_ids": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"vnic_group_ids": {
+					Type:        schema.TypeSet,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "vNIC groups, e.g. vse, internal, external or vnic-index-1",
+				},
+			},
+		},
+	}
+}
+
+func expandStringSet(v interface{}) []string {
+	var values []string
+	for _, value := range v.(*schema.Set).List() {
+		values = append(values, value.(string))
+	}
+	return values
+}
+
+func schemaToEdgeFirewallRuleMatch(v interface{}) *edgeFirewallRuleMatch {
+	matches := v.([]interface{})
+	if len(matches) == 0 || matches[0] == nil {
+		return nil
+	}
+	match := matches[0].(map[string]interface{})
+
+	return &edgeFirewallRuleMatch{
+		Exclude:           match["exclude"].(bool),
+		IPAddresses:       expandStringSet(match["ip_addresses"]),
+		GroupingObjectIDs: expandStringSet(match["grouping_object_ids"]),
+		VnicGroupIDs:      expandStringSet(match["vnic_group_ids"]),
+	}
+}
+
+func edgeFirewallRuleMatchToSchema(match *edgeFirewallRuleMatch) []map[string]interface{} {
+	if match == nil {
+		return nil
+	}
+	return []map[string]interface{}{
+		{
+			"exclude":             match.Exclude,
+			"ip_addresses":        match.IPAddresses,
+			"grouping_object_ids": match.GroupingObjectIDs,
+			"vnic_group_ids":      match.VnicGroupIDs,
+		},
+	}
+}
+
+func tfToEdgeFirewallRule(d *schema.ResourceData) edgeFirewallRule {
+	rule := edgeFirewallRule{
+		Name:            d.Get("name").(string),
+		Description:     d.Get("description").(string),
+		Action:          d.Get("action").(string),
+		Enabled:         d.Get("enabled").(bool),
+		LoggingEnabled:  d.Get("logging_enabled").(bool),
+		Direction:       d.Get("direction").(string),
+		MatchTranslated: d.Get("match_translated").(bool),
+		RuleTag:         d.Get("rule_tag").(int),
+		Source:          schemaToEdgeFirewallRuleMatch(d.Get("source")),
+		Destination:     schemaToEdgeFirewallRuleMatch(d.Get("destination")),
+	}
+
+	application := new(edgeFirewallApplication)
+	application.ApplicationIDs = expandStringSet(d.Get("application_ids"))
+	for _, service := range getListOfStructs(d.Get("service")) {
+		application.Services = append(application.Services, edgeFirewallService{
+			Protocol:   service["protocol"].(string),
+			Port:       service["port"].(string),
+			SourcePort: service["source_port"].(string),
+			IcmpType:   service["icmp_type"].(string),
+		})
+	}
+	if len(application.ApplicationIDs) > 0 || len(application.Services) > 0 {
+		rule.Application = application
+	}
+
+	return rule
+}
+
+func resourceEdgeFirewallRuleCreate(d *schema.ResourceData, m interface{}) error {
+	nsxclient := m.(*gonsx.NSXClient)
+	edgeid := d.Get("edgeid").(string)
+
+	nsxMutexKV.Lock(edgeid)
+	defer nsxMutexKV.Unlock(edgeid)
+
+	createAPI := newCreateEdgeFirewallRule(edgeid, d.Get("insert_above").(string), tfToEdgeFirewallRule(d))
+	err := nsxclient.Do(createAPI)
+	if err != nil {
+		return err
+	}
+
+	err = checkerr(createAPI)
+	if err != nil {
+		return fmt.Errorf("Failed to create edge firewall rule on %s: %s", edgeid, err)
+	}
+
+	ruleID := createAPI.RuleID()
+	if ruleID == "" {
+		return fmt.Errorf("Failed to create edge firewall rule on %s: no rule ID in the response", edgeid)
+	}
+
+	d.SetId(ruleID)
+	return resourceEdgeFirewallRuleRead(d, m)
+}
+
+func resourceEdgeFirewallRuleRead(d *schema.ResourceData, m interface{}) error {
+	nsxclient := m.(*gonsx.NSXClient)
+	edgeid := d.Get("edgeid").(string)
+
+	getAPI := newGetEdgeFirewallRule(edgeid, d.Id())
+	err := nsxclient.Do(getAPI)
+	if err != nil {
+		return err
+	}
+
+	// If the rule has been removed manually, notify Terraform of this fact.
+	if getAPI.StatusCode() == http.StatusNotFound {
+		log.Printf("[DEBUG] edge firewall rule %s not found on %s, removing it from state", d.Id(), edgeid)
+		d.SetId("")
+		return nil
+	}
+
+	err = checkerr(getAPI)
+	if err != nil {
+		return err
+	}
+
+	rule := getAPI.GetResponse()
+	d.Set("name", rule.Name)
+	d.Set("description", rule.Description)
+	d.Set("action", rule.Action)
+	d.Set("enabled", rule.Enabled)
+	d.Set("logging_enabled", rule.LoggingEnabled)
+	d.Set("direction", rule.Direction)
+	d.Set("match_translated", rule.MatchTranslated)
+	d.Set("rule_tag", rule.RuleTag)
+	d.Set("source", edgeFirewallRuleMatchToSchema(rule.Source))
+	d.Set("destination", edgeFirewallRuleMatchToSchema(rule.Destination))
+
+	var applicationIDs []string
+	services := make([]map[string]interface{}, 0)
+	if rule.Application != nil {
+		applicationIDs = rule.Application.ApplicationIDs
+		for _, service := range rule.Application.Services {
+			services = append(services, map[string]interface{}{
+				"protocol":    service.Protocol,
+				"port":        service.Port,
+				"source_port": service.SourcePort,
+				"icmp_type":   service.IcmpType,
+			})
+		}
+	}
+	d.Set("application_ids", applicationIDs)
+	return d.Set("service", services)
+}
+
+func resourceEdgeFirewallRuleUpdate(d *schema.ResourceData, m interface{}) error {
+	nsxclient := m.(*gonsx.NSXClient)
+	edgeid := d.Get("edgeid").(string)
+
+	nsxMutexKV.Lock(edgeid)
+	defer nsxMutexKV.Unlock(edgeid)
+
+	updateAPI := newUpdateEdgeFirewallRule(edgeid, d.Id(), tfToEdgeFirewallRule(d))
+	err := nsxclient.Do(updateAPI)
+	if err != nil {
+		return err
+	}
+
+	err = checkerr(updateAPI)
+	if err != nil {
+		return fmt.Errorf("Error updating edge firewall rule %s on %s: %s", d.Id(), edgeid, err)
+	}
+
+	return resourceEdgeFirewallRuleRead(d, m)
+}
+
+func resourceEdgeFirewallRuleDelete(d *schema.ResourceData, m interface{}) error {
+	nsxclient := m.(*gonsx.NSXClient)
+	edgeid := d.Get("edgeid").(string)
+
+	nsxMutexKV.Lock(edgeid)
+	defer nsxMutexKV.Unlock(edgeid)
+
+	deleteAPI := newDeleteEdgeFirewallRule(edgeid, d.Id())
+	err := nsxclient.Do(deleteAPI)
+	if err != nil {
+		return err
+	}
+
+	// Deleting a rule that is already gone is not an error.
+	if deleteAPI.StatusCode() != http.StatusNotFound {
+		err = checkerr(deleteAPI)
+		if err != nil {
+			return fmt.Errorf("Error deleting edge firewall rule %s on %s: %s", d.Id(), edgeid, err)
+		}
+	}
+
+	log.Printf("[DEBUG] edge firewall rule %s deleted from %s.", d.Id(), edgeid)
+	d.SetId("")
+	return nil
+}
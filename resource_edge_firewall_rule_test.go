@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/sky-uk/gonsx"
+)
+
+func TestAccNSXEdgeFirewallRuleBasic(t *testing.T) {
+
+	edgeid := "edge-5"
+	ruleName := fmt.Sprintf("acctest-nsx-edge-firewall-rule-%d", acctest.RandInt())
+	testResourceName := "nsx_edge_firewall_rule.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccNSXEdgeFirewallRuleCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNSXEdgeFirewallRuleTemplate(edgeid, ruleName, "accept"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccNSXEdgeFirewallRuleExists(testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "name", ruleName),
+					resource.TestCheckResourceAttr(testResourceName, "action", "accept"),
+				),
+			},
+			{
+				Config: testAccNSXEdgeFirewallRuleTemplate(edgeid, ruleName, "deny"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccNSXEdgeFirewallRuleExists(testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "action", "deny"),
+				),
+			},
+		},
+	})
+}
+
+func testAccNSXEdgeFirewallRuleExists(resourceName string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+
+		nsxClient := testAccProvider.Meta().(*gonsx.NSXClient)
+
+		rs, ok := state.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("NSX edge firewall rule resource %s not found in resources", resourceName)
+		}
+
+		getAPI := newGetEdgeFirewallRule(rs.Primary.Attributes["edgeid"], rs.Primary.ID)
+		err := nsxClient.Do(getAPI)
+		if err != nil {
+			return fmt.Errorf("Error while retrieving edge firewall rule %s. Error: %v", rs.Primary.ID, err)
+		}
+		if getAPI.StatusCode() != http.StatusOK {
+			return fmt.Errorf("Error while checking if edge firewall rule %s exists. HTTP return code was %d", rs.Primary.ID, getAPI.StatusCode())
+		}
+		return nil
+	}
+}
+
+func testAccNSXEdgeFirewallRuleCheckDestroy(state *terraform.State) error {
+
+	nsxClient := testAccProvider.Meta().(*gonsx.NSXClient)
+
+	for _, rs := range state.RootModule().Resources {
+
+		if rs.Type != "nsx_edge_firewall_rule" {
+			continue
+		}
+
+		getAPI := newGetEdgeFirewallRule(rs.Primary.Attributes["edgeid"], rs.Primary.ID)
+		err := nsxClient.Do(getAPI)
+		if err != nil {
+			return fmt.Errorf("Error while retrieving edge firewall rule %s. Error: %v", rs.Primary.ID, err)
+		}
+
+		if getAPI.StatusCode() != http.StatusNotFound {
+			return fmt.Errorf("NSX edge firewall rule %s still exists", rs.Primary.ID)
+		}
+	}
+	return nil
+}
+
+func testAccNSXEdgeFirewallRuleTemplate(edgeid, name, action string) string {
+	return fmt.Sprintf(`
+resource "nsx_edge_firewall_rule" "acctest" {
+edgeid = "%s"
+name = "%s"
+action = "%s"
+
+source {
+  ip_addresses = ["10.0.0.0/24"]
+}
+
+service {
+  protocol = "tcp"
+  port = "443"
+}
+}`, edgeid, name, action)
+}
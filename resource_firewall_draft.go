@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+	"github.com/sky-uk/gonsx/api"
+)
+
+func resourceFirewallDraft() *schema.Resource {
+
+	return &schema.Resource{
+
+		Create: resourceFirewallDraftCreate,
+		Read:   resourceFirewallDraftRead,
+		Update: resourceFirewallDraftUpdate,
+		Delete: resourceFirewallDraftDelete,
+
+		CustomizeDiff: resourceFirewallDraftCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"preserve": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Keep the draft when NSX prunes its old drafts",
+			},
+			"rollback_trigger": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Restore the firewall configuration saved in the newest snapshot whenever this value changes to a non empty one, e.g. a timestamp",
+			},
+			"timestamp": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Time the configuration was saved, in milliseconds since the epoch",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values, a new snapshot of the configuration is taken whenever they change",
+			},
+			"keep": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Number of snapshots kept, the older ones are pruned in the apply that takes a new one",
+			},
+			"snapshot_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the drafts of the snapshots kept, the newest last",
+			},
+		},
+	}
+}
+
+func resourceFirewallDraftCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && (d.HasChange("triggers") || d.HasChange("keep")) {
+		d.SetNewComputed("snapshot_ids")
+	}
+	return nil
+}
+
+func getFirewallDraft(nsxclient *gonsx.NSXClient, draftID string) (*firewallDraft, error) {
+	getAPI := newGetFirewallDraft(draftID)
+	err := nsxclient.Do(getAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	if getAPI.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+
+	err = checkerr(getAPI)
+	if err != nil {
+		return nil, err
+	}
+	return getAPI.GetResponse(), nil
+}
+
+// saveFirewallDraft saves the current firewall configuration in a new draft
+// and returns its ID.
+func saveFirewallDraft(d *schema.ResourceData, nsxclient *gonsx.NSXClient) (string, error) {
+	// Saved under the firewall lock so that the draft doesn't catch a
+	// change half way.
+	nsxMutexKV.Lock(firewallMutexKey)
+	defer nsxMutexKV.Unlock(firewallMutexKey)
+
+	configAPI := newGetFirewallConfiguration()
+	err := nsxclient.Do(configAPI)
+	if err != nil {
+		return "", err
+	}
+	err = checkerr(configAPI)
+	if err != nil {
+		return "", err
+	}
+
+	draft := &firewallDraft{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Preserve:    d.Get("preserve").(bool),
+		Mode:        "userdefined",
+		Config: &firewallDraftConfig{
+			Inner: configAPI.GetResponse().Inner,
+		},
+	}
+
+	createAPI := newCreateFirewallDraft(draft)
+	err = nsxclient.Do(createAPI)
+	if err != nil {
+		return "", err
+	}
+	err = checkerr(createAPI)
+	if err != nil {
+		return "", fmt.Errorf("Error saving firewall draft %s: %s", draft.Name, err)
+	}
+
+	return createAPI.GetResponse().ID, nil
+}
+
+// deleteFirewallDraft deletes a draft, a draft that is already gone is not
+// an error.
+func deleteFirewallDraft(nsxclient *gonsx.NSXClient, draftID string) error {
+	deleteAPI := newDeleteFirewallDraft(draftID)
+	err := nsxclient.Do(deleteAPI)
+	if err != nil {
+		return err
+	}
+
+	if deleteAPI.StatusCode() != http.StatusNotFound {
+		err = checkerr(deleteAPI)
+		if err != nil {
+			return fmt.Errorf("Error deleting firewall draft %s: %s", draftID, err)
+		}
+	}
+	return nil
+}
+
+// firewallDraftSnapshots returns the IDs of the drafts saved by the
+// resource, the newest last.
+func firewallDraftSnapshots(d *schema.ResourceData) []string {
+	// snapshot_ids is only known after the apply when it changes.
+	old, _ := d.GetChange("snapshot_ids")
+
+	var ids []string
+	for _, id := range old.([]interface{}) {
+		ids = append(ids, id.(string))
+	}
+	// States written before snapshot_ids existed only know the current draft.
+	if len(ids) == 0 && d.Id() != "" {
+		ids = []string{d.Id()}
+	}
+	return ids
+}
+
+// pruneFirewallDrafts deletes the oldest drafts of ids until keep are left,
+// and returns the IDs left.
+func pruneFirewallDrafts(nsxclient *gonsx.NSXClient, ids []string, keep int) ([]string, error) {
+	for len(ids) > keep {
+		log.Printf("[DEBUG] Pruning firewall draft %s", ids[0])
+		err := deleteFirewallDraft(nsxclient, ids[0])
+		if err != nil {
+			return ids, err
+		}
+		ids = ids[1:]
+	}
+	return ids, nil
+}
+
+func resourceFirewallDraftCreate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	draftID, err := saveFirewallDraft(d, nsxclient)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(draftID)
+	d.Set("snapshot_ids", []string{draftID})
+	return resourceFirewallDraftRead(d, meta)
+}
+
+func resourceFirewallDraftRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	var ids []string
+	for _, id := range d.Get("snapshot_ids").([]interface{}) {
+		ids = append(ids, id.(string))
+	}
+	if len(ids) == 0 {
+		ids = []string{d.Id()}
+	}
+
+	// If the newest draft has been removed manually, fall back to the newest
+	// one left.
+	var draft *firewallDraft
+	for len(ids) > 0 && draft == nil {
+		var err error
+		draft, err = getFirewallDraft(nsxclient, ids[len(ids)-1])
+		if err != nil {
+			return err
+		}
+		if draft == nil {
+			log.Printf("[DEBUG] firewall draft %s not found", ids[len(ids)-1])
+			ids = ids[:len(ids)-1]
+		}
+	}
+
+	// If every draft has been removed manually, notify Terraform of this fact.
+	if draft == nil {
+		log.Printf("[DEBUG] firewall drafts of %s not found, removing it from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.SetId(ids[len(ids)-1])
+	d.Set("snapshot_ids", ids)
+	d.Set("name", draft.Name)
+	d.Set("description", draft.Description)
+	d.Set("preserve", draft.Preserve)
+	d.Set("timestamp", int(draft.Timestamp))
+	return nil
+}
+
+func resourceFirewallDraftUpdate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	draft, err := getFirewallDraft(nsxclient, d.Id())
+	if err != nil {
+		return err
+	}
+	if draft == nil {
+		return fmt.Errorf("firewall draft %s not found", d.Id())
+	}
+
+	if d.HasChange("name") || d.HasChange("description") || d.HasChange("preserve") {
+		draft.Name = d.Get("name").(string)
+		draft.Description = d.Get("description").(string)
+		draft.Preserve = d.Get("preserve").(bool)
+
+		updateAPI := newUpdateFirewallDraft(draft)
+		err = nsxclient.Do(updateAPI)
+		if err != nil {
+			return err
+		}
+		err = checkerr(updateAPI)
+		if err != nil {
+			return fmt.Errorf("Error updating firewall draft %s: %s", d.Id(), err)
+		}
+	}
+
+	if d.HasChange("rollback_trigger") && d.Get("rollback_trigger").(string) != "" {
+		if draft.Config == nil {
+			return fmt.Errorf("firewall draft %s has no configuration to restore", d.Id())
+		}
+
+		log.Printf("[DEBUG] Restoring the firewall configuration saved in draft %s", d.Id())
+		err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+			etag, err := getFirewallEtag(nsxclient)
+			if err != nil {
+				return nil, err
+			}
+			return newUpdateFirewallConfiguration(etag, &firewallConfiguration{Inner: draft.Config.Inner}), nil
+		})
+		if err != nil {
+			return fmt.Errorf("Error restoring firewall draft %s: %s", d.Id(), err)
+		}
+	}
+
+	// A new snapshot is taken after any rollback, so that the rollback
+	// restores the previous one.
+	ids := firewallDraftSnapshots(d)
+	if d.HasChange("triggers") {
+		draftID, err := saveFirewallDraft(d, nsxclient)
+		if err != nil {
+			return err
+		}
+		log.Printf("[DEBUG] Saved firewall snapshot %s", draftID)
+		d.SetId(draftID)
+		ids = append(ids, draftID)
+	}
+
+	ids, err = pruneFirewallDrafts(nsxclient, ids, d.Get("keep").(int))
+	d.Set("snapshot_ids", ids)
+	if err != nil {
+		return err
+	}
+
+	return resourceFirewallDraftRead(d, meta)
+}
+
+func resourceFirewallDraftDelete(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	ids, err := pruneFirewallDrafts(nsxclient, firewallDraftSnapshots(d), 0)
+	if err != nil {
+		d.Set("snapshot_ids", ids)
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
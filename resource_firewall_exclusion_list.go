@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sky-uk/gonsx"
+	"github.com/sky-uk/gonsx/api/firewallexclusion"
+)
+
+// firewallExclusionListID is the ID of the nsx_firewall_exclusion_list
+// singleton.
+const firewallExclusionListID = "excludelist"
+
+func resourceFirewallExclusionList() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceFirewallExclusionListUpdate,
+		Read:   resourceFirewallExclusionListRead,
+		Update: resourceFirewallExclusionListUpdate,
+		Delete: resourceFirewallExclusionListDelete,
+
+		Schema: map[string]*schema.Schema{
+			"moids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Every member of the exclusion list, any other member is removed",
+			},
+			"added_moids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Members added to the exclusion list by this resource, the only ones removed on destroy",
+			},
+		},
+	}
+}
+
+func getAllFirewallExclusions(nsxclient *gonsx.NSXClient) (*firewallexclusion.FirewallExclusions, error) {
+	getAllAPI := firewallexclusion.NewGetAll()
+	err := nsxclient.Do(getAllAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	if getAllAPI.StatusCode() != 200 {
+		return nil, fmt.Errorf("Status code: %d, Response: %s", getAllAPI.StatusCode(), getAllAPI.ResponseObject())
+	}
+
+	return getAllAPI.GetResponse(), nil
+}
+
+// firewallExclusionMoids returns the members of the exclusion list.
+func firewallExclusionMoids(exclusions *firewallexclusion.FirewallExclusions) *schema.Set {
+	moids := schema.NewSet(schema.HashString, nil)
+	for _, member := range exclusions.Members {
+		moids.Add(member.MOID)
+	}
+	return moids
+}
+
+// setFirewallExclusions adds and removes members of the exclusion list until
+// it holds exactly moids, and returns the members it added.
+func setFirewallExclusions(nsxclient *gonsx.NSXClient, moids *schema.Set) (*schema.Set, error) {
+	exclusions, err := getAllFirewallExclusions(nsxclient)
+	if err != nil {
+		return nil, err
+	}
+
+	current := firewallExclusionMoids(exclusions)
+	err = removeFirewallExclusions(nsxclient, current.Difference(moids))
+	if err != nil {
+		return nil, err
+	}
+
+	added := moids.Difference(current)
+	for _, moid := range added.List() {
+		log.Printf("[DEBUG] firewallexclusion.NewCreate(%s)", moid)
+		createAPI := firewallexclusion.NewCreate(moid.(string))
+		err = nsxclient.Do(createAPI)
+		if err != nil {
+			return nil, err
+		}
+		err = checkerr(createAPI)
+		if err != nil {
+			return nil, fmt.Errorf("Error adding %s to the firewall exclusion list: %s", moid, err)
+		}
+	}
+
+	return added, nil
+}
+
+// removeFirewallExclusions removes moids from the exclusion list.
+func removeFirewallExclusions(nsxclient *gonsx.NSXClient, moids *schema.Set) error {
+	for _, moid := range moids.List() {
+		log.Printf("[DEBUG] firewallexclusion.NewDelete(%s)", moid)
+		deleteAPI := firewallexclusion.NewDelete(moid.(string))
+		err := nsxclient.Do(deleteAPI)
+		if err != nil {
+			return err
+		}
+		err = checkerr(deleteAPI)
+		if err != nil {
+			return fmt.Errorf("Error removing %s from the firewall exclusion list: %s", moid, err)
+		}
+	}
+	return nil
+}
+
+func resourceFirewallExclusionListRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	exclusions, err := getAllFirewallExclusions(nsxclient)
+	if err != nil {
+		return err
+	}
+
+	// Members added by this resource and removed since then are no longer
+	// its own.
+	moids := firewallExclusionMoids(exclusions)
+	d.Set("added_moids", d.Get("added_moids").(*schema.Set).Intersection(moids))
+	return d.Set("moids", moids)
+}
+
+func resourceFirewallExclusionListUpdate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	moids := d.Get("moids").(*schema.Set)
+	added, err := setFirewallExclusions(nsxclient, moids)
+	if err != nil {
+		return err
+	}
+
+	// Members removed from the configuration have been removed from the
+	// list, whoever added them.
+	d.Set("added_moids", d.Get("added_moids").(*schema.Set).Union(added).Intersection(moids))
+	d.SetId(firewallExclusionListID)
+	return resourceFirewallExclusionListRead(d, meta)
+}
+
+func resourceFirewallExclusionListDelete(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	// Members that were in the list before this resource, or that
+	// nsx_firewall_exclusion added, stay.
+	exclusions, err := getAllFirewallExclusions(nsxclient)
+	if err != nil {
+		return err
+	}
+	err = removeFirewallExclusions(nsxclient, d.Get("added_moids").(*schema.Set).Intersection(firewallExclusionMoids(exclusions)))
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/sky-uk/gonsx"
+	"github.com/sky-uk/gonsx/api"
+)
+
+// firewallGlobalConfigID is the ID of the nsx_firewall_global_config
+// singleton.
+const firewallGlobalConfigID = "globalroot-0"
+
+// firewallGlobalOptions maps the attributes of nsx_firewall_global_config to
+// the options of the global configuration.
+var firewallGlobalOptions = map[string]string{
+	"layer3_rule_optimize": "layer3RuleOptimize",
+	"layer2_rule_optimize": "layer2RuleOptimize",
+	"tcp_strict":           "tcpStrictOption",
+	"auto_draft_disabled":  "autoDraftDisabled",
+}
+
+func resourceFirewallGlobalConfig() *schema.Resource {
+
+	return &schema.Resource{
+
+		Create: resourceFirewallGlobalConfigUpdate,
+		Read:   resourceFirewallGlobalConfigRead,
+		Update: resourceFirewallGlobalConfigUpdate,
+		Delete: resourceFirewallGlobalConfigDelete,
+
+		// NSX has no global stateless default, stateless firewalling is an
+		// option of each section, see nsx_firewall_section.
+		Schema: map[string]*schema.Schema{
+			"layer3_rule_optimize": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"layer2_rule_optimize": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"tcp_strict": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"auto_draft_disabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"cluster": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Clusters the distributed firewall is enabled or disabled on, the other clusters are left as they are",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cluster_id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getFirewallGlobalConfiguration(nsxclient *gonsx.NSXClient) (*firewallGlobalConfiguration, error) {
+	getAPI := newGetFirewallGlobalConfiguration()
+	err := nsxclient.Do(getAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkerr(getAPI)
+	if err != nil {
+		return nil, err
+	}
+	return getAPI.GetResponse(), nil
+}
+
+// isFirewallEnabled tells if the distributed firewall is enabled on a cluster.
+func isFirewallEnabled(nsxclient *gonsx.NSXClient, clusterID string) (bool, error) {
+	statusAPI := newGetFabricStatus(clusterID)
+	err := nsxclient.Do(statusAPI)
+	if err != nil {
+		return false, err
+	}
+
+	err = checkerr(statusAPI)
+	if err != nil {
+		return false, err
+	}
+
+	for _, status := range statusAPI.GetResponse().Statuses {
+		for _, feature := range status.Features {
+			if feature.FeatureID == firewallFeatureID {
+				return feature.Enabled, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func resourceFirewallGlobalConfigRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	config, err := getFirewallGlobalConfiguration(nsxclient)
+	if err != nil {
+		return err
+	}
+
+	for key, option := range firewallGlobalOptions {
+		d.Set(key, config.Bool(option))
+	}
+
+	clusters := make([]map[string]interface{}, 0)
+	for _, cluster := range getListOfStructs(d.Get("cluster")) {
+		clusterID := cluster["cluster_id"].(string)
+		enabled, err := isFirewallEnabled(nsxclient, clusterID)
+		if err != nil {
+			return err
+		}
+		clusters = append(clusters, map[string]interface{}{
+			"cluster_id": clusterID,
+			"enabled":    enabled,
+		})
+	}
+	return d.Set("cluster", clusters)
+}
+
+func resourceFirewallGlobalConfigUpdate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	err := firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		config, err := getFirewallGlobalConfiguration(nsxclient)
+		if err != nil {
+			return nil, err
+		}
+
+		// Options left out of the configuration, and those the provider
+		// doesn't know, keep their current value.
+		for key, option := range firewallGlobalOptions {
+			if v, ok := d.GetOkExists(key); ok {
+				config.SetBool(option, v.(bool))
+			}
+		}
+
+		return newUpdateFirewallGlobalConfiguration(config), nil
+	})
+	if err != nil {
+		return fmt.Errorf("Error updating the firewall global configuration: %s", err)
+	}
+
+	oldClusters, newClusters := d.GetChange("cluster")
+	for _, cluster := range newClusters.(*schema.Set).Difference(oldClusters.(*schema.Set)).List() {
+		clusterID := cluster.(map[string]interface{})["cluster_id"].(string)
+		enabled := cluster.(map[string]interface{})["enabled"].(bool)
+
+		log.Printf("[DEBUG] Setting the distributed firewall of cluster %s enabled: %t", clusterID, enabled)
+		err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+			return newEnableFirewall(clusterID, enabled), nil
+		})
+		if err != nil {
+			return fmt.Errorf("Error enabling the distributed firewall on cluster %s: %s", clusterID, err)
+		}
+	}
+
+	d.SetId(firewallGlobalConfigID)
+	return resourceFirewallGlobalConfigRead(d, meta)
+}
+
+func resourceFirewallGlobalConfigDelete(d *schema.ResourceData, meta interface{}) error {
+	// The global configuration can't be removed, it is only forgotten.
+	log.Printf("[DEBUG] Removing the firewall global configuration from state, NSX keeps its current values")
+	d.SetId("")
+	return nil
+}
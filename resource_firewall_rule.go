@@ -2,6 +2,10 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -9,10 +13,45 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/sky-uk/gonsx"
+	"github.com/sky-uk/gonsx/api"
 	"github.com/sky-uk/gonsx/api/firewall"
 )
 
 func resourceFirewallRule() *schema.Resource {
+	ruleSchema := firewallRuleSchema()
+	ruleSchema["sectionid"] = &schema.Schema{
+		Type:        schema.TypeInt,
+		Required:    true,
+		ForceNew:    true,
+		Description: "ID of the section of the rule, a rule can't move to another section",
+	}
+	ruleSchema["etag"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "ETag of the section of the rule",
+	}
+	ruleSchema["layer"] = schemaFirewallRuleLayer()
+	ruleSchema["insert_before"] = &schema.Schema{
+		Type:          schema.TypeInt,
+		Optional:      true,
+		ConflictsWith: []string{"insert_after", "position"},
+		Description:   "ID of a rule of the same section this rule must be placed before",
+	}
+	ruleSchema["insert_after"] = &schema.Schema{
+		Type:          schema.TypeInt,
+		Optional:      true,
+		ConflictsWith: []string{"insert_before", "position"},
+		Description:   "ID of a rule of the same section this rule must be placed after",
+	}
+	ruleSchema["position"] = &schema.Schema{
+		Type:          schema.TypeInt,
+		Optional:      true,
+		ValidateFunc:  validation.IntAtLeast(1),
+		ConflictsWith: []string{"insert_before", "insert_after"},
+		Description:   "Position of the rule in its section, from 1 to the number of rules of the section",
+	}
+	ruleSchema["universal"] = schemaFirewallRuleUniversal()
+	ruleSchema["wait_for_publish"] = schemaWaitForPublish()
 
 	return &schema.Resource{
 
@@ -21,121 +60,479 @@ func resourceFirewallRule() *schema.Resource {
 		Update: resourceFirewallRuleUpdate,
 		Delete: resourceFirewallRuleDelete,
 
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:     schema.TypeString,
-				Required: true,
-			},
-			"description": {
-				Type:     schema.TypeString,
-				Optional: true,
-			},
-			"disabled": {
-				Type:     schema.TypeBool,
-				Optional: true,
-			},
-			"logged": {
-				Type:     schema.TypeBool,
-				Optional: true,
-			},
-			"action": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "allow",
-				ValidateFunc: validation.StringInSlice([]string{
-					"allow",
-					"deny",
-					"reject",
-				}, true),
-			},
-			"direction": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "inout",
-				ValidateFunc: validation.StringInSlice([]string{
-					"in",
-					"out",
-					"inout",
-				}, true),
-			},
-			"sectionid": {
-				Type:     schema.TypeInt,
-				Required: true,
-			},
-			"etag": {
-				Type:     schema.TypeString,
-				Computed: true,
-			},
-			"packet_type": {
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "any",
-			},
-			"applied_to": {
-				Type:       schema.TypeSet,
-				ConfigMode: schema.SchemaConfigModeAttr,
-				Optional:   true,
-				Computed:   true,
-				Set:        setRuleElement,
-				Elem:       schemaRuleElement(),
-			},
-			"source": {
-				Type:          schema.TypeSet,
-				ConfigMode:    schema.SchemaConfigModeAttr,
-				Optional:      true,
-				Set:           setRuleElement,
-				Elem:          schemaRuleElement(),
-				ConflictsWith: []string{"source_excluded"},
-			},
-			"source_excluded": {
-				Type:          schema.TypeSet,
-				ConfigMode:    schema.SchemaConfigModeAttr,
-				Optional:      true,
-				Set:           setRuleElement,
-				Elem:          schemaRuleElement(),
-				ConflictsWith: []string{"source"},
-			},
-			"destination": {
-				Type:          schema.TypeSet,
-				ConfigMode:    schema.SchemaConfigModeAttr,
-				Optional:      true,
-				Set:           setRuleElement,
-				Elem:          schemaRuleElement(),
-				ConflictsWith: []string{"destination_excluded"},
-			},
-			"destination_excluded": {
-				Type:          schema.TypeSet,
-				ConfigMode:    schema.SchemaConfigModeAttr,
-				Optional:      true,
-				Set:           setRuleElement,
-				Elem:          schemaRuleElement(),
-				ConflictsWith: []string{"destination"},
-			},
-			"service": {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Set:      setRuleElement,
-				Elem:     schemaRuleElement(),
+		CustomizeDiff: resourceFirewallRuleCustomizeDiff,
+
+		Timeouts: firewallResourceTimeouts(),
+
+		Schema: ruleSchema,
+	}
+}
+
+func schemaFirewallRuleLayer() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "LAYER3",
+		ForceNew:    true,
+		Description: "Type of the section the rules belong to, LAYER3 or LAYER2",
+		ValidateFunc: validation.StringInSlice([]string{
+			"LAYER3",
+			"LAYER2",
+		}, false),
+	}
+}
+
+// firewallRuleLayer returns the layer of the section of a rule, rules in
+// states written before the layer attribute existed are layer 3 ones.
+func firewallRuleLayer(d *schema.ResourceData) string {
+	if layer, ok := d.GetOk("layer"); ok {
+		return layer.(string)
+	}
+	return "LAYER3"
+}
+
+// layer3ElementTypes can't be matched by layer 2 rules.
+var layer3ElementTypes = map[string]bool{
+	"IPSet":       true,
+	"Ipv4Address": true,
+	"Ipv6Address": true,
+}
+
+// edgeElementTypes can't be the target of layer 2 rules, edges only enforce
+// layer 3 rules.
+var edgeElementTypes = map[string]bool{
+	"ALL_EDGES": true,
+	"Edge":      true,
+}
+
+// firewallEthertypes maps the ethernet types layer 2 rules can match to
+// their value.
+var firewallEthertypes = map[string]int{
+	"IPV4":  0x0800,
+	"ARP":   0x0806,
+	"RARP":  0x8035,
+	"IPX":   0x8137,
+	"IPV6":  0x86DD,
+	"MPLS":  0x8847,
+	"PPPOE": 0x8864,
+	"LLDP":  0x88CC,
+}
+
+func resourceFirewallRuleCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	return validateFirewallRule(d.Get("layer").(string), d.Get("universal").(bool), d, "")
+}
+
+// schemaFirewallRuleUniversal is the schema of the universal attribute of
+// the resources managing rules.
+func schemaFirewallRuleUniversal() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "The section of the rules is universal, its rules can only reference universal objects",
+	}
+}
+
+// checkFirewallSectionScope makes sure a section is universal when the rules
+// written to it are, and the other way around.
+func checkFirewallSectionScope(section *firewallSection, universal bool) error {
+	if (section.ManagedBy == universalScopeID) != universal {
+		if universal {
+			return fmt.Errorf("firewall section %d is not universal", section.ID)
+		}
+		return fmt.Errorf("firewall section %d is universal, set universal to true", section.ID)
+	}
+	return nil
+}
+
+// checkFirewallRuleSection makes sure the section a rule is written to
+// exists and has the scope of the rule.
+func checkFirewallRuleSection(nsxclient *gonsx.NSXClient, layer string, sectionID int, universal bool) error {
+	section, _, err := getFirewallSection(layer, sectionID, nsxclient)
+	if err != nil {
+		return err
+	}
+	if section == nil {
+		return fmt.Errorf("firewall section %d not found", sectionID)
+	}
+	return checkFirewallSectionScope(section, universal)
+}
+
+// ruleElementKeys are the attributes of a rule holding rule elements.
+var ruleElementKeys = []string{"applied_to", "source", "source_excluded", "destination", "destination_excluded", "service"}
+
+// ruleElementIDPatterns are the ID formats of the objects a rule element can
+// reference.
+var ruleElementIDPatterns = map[string]*regexp.Regexp{
+	"SecurityGroup": regexp.MustCompile(`^securitygroup-(\d+|` + universalIDSuffix + `)$`),
+	"IPSet":         regexp.MustCompile(`^ipset-(\d+|` + universalIDSuffix + `)$`),
+	"VirtualWire":   regexp.MustCompile(`^(virtual|universal)wire-\d+$`),
+	"Edge":          regexp.MustCompile(`^edge-\d+$`),
+}
+
+// universalRuleElementTypes are the element types universal rules can use
+// without referencing an object.
+var universalRuleElementTypes = map[string]bool{
+	"Ipv4Address":          true,
+	"Ipv6Address":          true,
+	"DISTRIBUTED_FIREWALL": true,
+}
+
+// unknownRuleElementValue is the value of an element that is interpolated
+// from an attribute not known at plan time.
+const unknownRuleElementValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+// validateRuleElementValue checks that value is usable for an element of
+// type elemType.
+func validateRuleElementValue(elemType, value string) error {
+	switch elemType {
+	case "Ipv4Address":
+		if !isIPAddressValue(value, false) {
+			return fmt.Errorf("%q is not an IPv4 address, CIDR or range", value)
+		}
+	case "Ipv6Address":
+		if !isIPAddressValue(value, true) {
+			return fmt.Errorf("%q is not an IPv6 address, CIDR or range", value)
+		}
+	default:
+		if pattern, ok := ruleElementIDPatterns[elemType]; ok && !pattern.MatchString(value) {
+			return fmt.Errorf("%q is not a %s ID, expected %s", value, elemType, pattern)
+		}
+	}
+	return nil
+}
+
+func validateFirewallEthertype(v interface{}, k string) (ws []string, errors []error) {
+	if _, ok := firewallEthertypes[v.(string)]; !ok {
+		errors = append(errors, fmt.Errorf("%q must be a valid ethernet type, e.g. ARP, IPV4 or IPV6", k))
+	}
+	return
+}
+
+// isIPAddressValue tells if value is an address, a CIDR or a range of
+// addresses of the given family.
+func isIPAddressValue(value string, ipv6 bool) bool {
+	isFamily := func(ip net.IP) bool {
+		return ip != nil && (ip.To4() == nil) == ipv6
+	}
+
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return isFamily(network.IP)
+	}
+
+	bounds := strings.Split(value, "-")
+	if len(bounds) > 2 {
+		return false
+	}
+	for _, bound := range bounds {
+		if !isFamily(net.ParseIP(strings.TrimSpace(bound))) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateFirewallRule checks the elements of a rule and the parts of the
+// rule that depend on the layer and the scope of its section, path prefixes
+// the attributes named in errors.
+func validateFirewallRule(layer string, universal bool, rule ruleAttributes, path string) error {
+	for _, key := range ruleElementKeys {
+		elements, ok := rule.Get(key).(*schema.Set)
+		if !ok {
+			continue
+		}
+		for _, elem := range getListOfStructs(elements) {
+			elemType, _ := elem["type"].(string)
+			value, _ := elem["value"].(string)
+			if value == "" || value == unknownRuleElementValue {
+				continue
+			}
+			if err := validateRuleElementValue(elemType, value); err != nil {
+				return fmt.Errorf("%s%s: %s", path, key, err)
+			}
+			if universal && !universalRuleElementTypes[elemType] {
+				if err := validateUniversalReference(value); err != nil {
+					return fmt.Errorf("%s%s: %s", path, key, err)
+				}
+			}
+		}
+	}
+
+	// nsx_firewall_rule rejects these with ConflictsWith, the rule blocks of
+	// nsx_firewall_section_rules can't.
+	for _, key := range []string{"source", "destination"} {
+		included, _ := rule.Get(key).(*schema.Set)
+		excluded, _ := rule.Get(key + "_excluded").(*schema.Set)
+		if included != nil && excluded != nil && included.Len() > 0 && excluded.Len() > 0 {
+			return fmt.Errorf("%s%s_excluded: conflicts with %s%s", path, key, path, key)
+		}
+	}
+
+	ethertypes, _ := rule.Get("ethertypes").(*schema.Set)
+	if layer != "LAYER2" {
+		if ethertypes != nil && ethertypes.Len() > 0 {
+			return fmt.Errorf("%sethertypes: only layer 2 rules match ethernet types", path)
+		}
+		return nil
+	}
+
+	if strings.EqualFold(rule.Get("action").(string), "reject") {
+		return fmt.Errorf("%saction: reject is not supported by layer 2 rules", path)
+	}
+
+	if inlineServices, ok := rule.Get("inline_service").(*schema.Set); ok && inlineServices.Len() > 0 {
+		service := getListOfStructs(inlineServices)[0]
+		return fmt.Errorf("%sinline_service: protocol %s can only be matched by layer 3 rules, use ethertypes", path, service["protocol"])
+	}
+
+	if elements, ok := rule.Get("applied_to").(*schema.Set); ok {
+		for _, elem := range getListOfStructs(elements) {
+			if elemType, _ := elem["type"].(string); edgeElementTypes[elemType] || layer3ElementTypes[elemType] {
+				return fmt.Errorf("%sapplied_to: layer 2 rules can't be applied to %s %q", path, elemType, elem["value"])
+			}
+		}
+	}
+
+	for _, key := range []string{"source", "source_excluded", "destination", "destination_excluded"} {
+		elements, ok := rule.Get(key).(*schema.Set)
+		if !ok {
+			continue
+		}
+		for _, elem := range getListOfStructs(elements) {
+			if elemType, _ := elem["type"].(string); layer3ElementTypes[elemType] {
+				return fmt.Errorf("%s%s: %s %q can only be used in layer 3 rules", path, key, elemType, elem["value"])
+			}
+		}
+	}
+	return nil
+}
+
+// firewallRuleSchema returns the attributes describing a single rule, shared
+// by nsx_firewall_rule and nsx_firewall_section_rules.
+func firewallRuleSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"disabled": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"logged": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"action": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "allow",
+			ValidateFunc: validation.StringInSlice([]string{
+				"allow",
+				"deny",
+				"reject",
+			}, true),
+		},
+		"direction": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "inout",
+			ValidateFunc: validation.StringInSlice([]string{
+				"in",
+				"out",
+				"inout",
+			}, true),
+		},
+		"packet_type": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Default:  "any",
+			ValidateFunc: validation.StringInSlice([]string{
+				"any",
+				"ipv4",
+				"ipv6",
+			}, true),
+		},
+		"ethertypes": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateFirewallEthertype},
+			Description: "Ethernet types matched by a layer 2 rule, e.g. ARP or IPV6, any when empty",
+		},
+		"applied_to": {
+			Type:       schema.TypeSet,
+			ConfigMode: schema.SchemaConfigModeAttr,
+			Optional:   true,
+			Computed:   true,
+			Set:        setRuleElement,
+			Elem:       schemaRuleElement(),
+		},
+		"source": {
+			Type:          schema.TypeSet,
+			ConfigMode:    schema.SchemaConfigModeAttr,
+			Optional:      true,
+			Set:           setRuleElement,
+			Elem:          schemaRuleElement(),
+			ConflictsWith: []string{"source_excluded"},
+		},
+		"source_excluded": {
+			Type:          schema.TypeSet,
+			ConfigMode:    schema.SchemaConfigModeAttr,
+			Optional:      true,
+			Set:           setRuleElement,
+			Elem:          schemaRuleElement(),
+			ConflictsWith: []string{"source"},
+		},
+		"destination": {
+			Type:          schema.TypeSet,
+			ConfigMode:    schema.SchemaConfigModeAttr,
+			Optional:      true,
+			Set:           setRuleElement,
+			Elem:          schemaRuleElement(),
+			ConflictsWith: []string{"destination_excluded"},
+		},
+		"destination_excluded": {
+			Type:          schema.TypeSet,
+			ConfigMode:    schema.SchemaConfigModeAttr,
+			Optional:      true,
+			Set:           setRuleElement,
+			Elem:          schemaRuleElement(),
+			ConflictsWith: []string{"destination"},
+		},
+		"service": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Set:      setRuleElement,
+			Elem:     schemaRuleElement(),
+		},
+		"inline_service": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Description: "Protocol and ports matched by the rule, without a service object",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"protocol": {
+						Type:     schema.TypeString,
+						Required: true,
+						ValidateFunc: validation.StringInSlice([]string{
+							"TCP",
+							"UDP",
+							"ICMP",
+							"IPV6ICMP",
+						}, false),
+					},
+					"destination_port": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"source_port": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"icmp_type": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+				},
 			},
 		},
 	}
 }
 
+// firewallServiceProtocols maps the protocols of inline services to their
+// IP protocol number.
+var firewallServiceProtocols = map[string]int{
+	"ICMP":     1,
+	"TCP":      6,
+	"UDP":      17,
+	"IPV6ICMP": 58,
+}
+
+func schemaInlineServiceToService(d interface{}) []firewallService {
+	schemaToListMap := getListOfStructs(d)
+	services := make([]firewallService, len(schemaToListMap))
+
+	for i, service := range schemaToListMap {
+		protocol := service["protocol"].(string)
+		services[i] = firewallService{
+			Protocol:        firewallServiceProtocols[protocol],
+			ProtocolName:    protocol,
+			DestinationPort: service["destination_port"].(string),
+			SourcePort:      service["source_port"].(string),
+			SubProtocolName: service["icmp_type"].(string),
+		}
+	}
+
+	return services
+}
+
+func serviceToSchemaInlineService(service firewallService) map[string]interface{} {
+	protocol := strings.ToUpper(service.ProtocolName)
+	if protocol == "" {
+		for name, number := range firewallServiceProtocols {
+			if number == service.Protocol {
+				protocol = name
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"protocol":         protocol,
+		"destination_port": service.DestinationPort,
+		"source_port":      service.SourcePort,
+		"icmp_type":        service.SubProtocolName,
+	}
+}
+
 func setRuleElement(v interface{}) int {
 	elem := v.(map[string]interface{})
 	return hashcode.String(fmt.Sprintf(
 		"%s-%d",
-		elem["value"],
+		canonicalRuleElementValue(elem["value"].(string)),
 		elem["type"],
 	))
 }
 
+// canonicalRuleElementValue returns the form NSX stores IP addresses, CIDRs
+// and ranges in, any other value is returned as is.
+func canonicalRuleElementValue(value string) string {
+	if ip, network, err := net.ParseCIDR(value); err == nil {
+		ones, bits := network.Mask.Size()
+		if ones == bits {
+			return ip.String()
+		}
+		return fmt.Sprintf("%s/%d", ip, ones)
+	}
+
+	bounds := strings.Split(value, "-")
+	if len(bounds) > 2 {
+		return value
+	}
+	for i, bound := range bounds {
+		ip := net.ParseIP(strings.TrimSpace(bound))
+		if ip == nil {
+			return value
+		}
+		bounds[i] = ip.String()
+	}
+	return strings.Join(bounds, "-")
+}
+
+func suppressEquivalentRuleElementValue(k, old, new string, d *schema.ResourceData) bool {
+	return canonicalRuleElementValue(old) == canonicalRuleElementValue(new)
+}
+
 func schemaRuleElement() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"value": &schema.Schema{
-				Type:     schema.TypeString,
-				Required: true,
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEquivalentRuleElementValue,
 			},
 			"type": &schema.Schema{
 				Type:     schema.TypeString,
@@ -196,7 +593,7 @@ func elementToSchemaRuleElement(elems []firewall.Element) []map[string]interface
 		elemsMap[i] = map[string]interface{}{
 			"name":     elem.Name,
 			"type":     elem.Type,
-			"value":    elem.Value,
+			"value":    canonicalRuleElementValue(elem.Value),
 			"is_valid": elem.IsValid,
 		}
 	}
@@ -204,12 +601,32 @@ func elementToSchemaRuleElement(elems []firewall.Element) []map[string]interface
 	return elemsMap
 }
 
-func tfRuleToFirewallRule(d *schema.ResourceData) firewall.Rule {
+// ruleAttributes gives access to the attributes of a rule, either those of
+// a nsx_firewall_rule or those of a rule block of nsx_firewall_section_rules.
+type ruleAttributes interface {
+	Get(key string) interface{}
+}
+
+// ruleAttributeMap wraps a rule block so that it can be used as ruleAttributes.
+type ruleAttributeMap map[string]interface{}
+
+func (m ruleAttributeMap) Get(key string) interface{} {
+	return m[key]
+}
+
+func tfRuleToFirewallRule(d *schema.ResourceData) firewallRule {
 	id, err := strconv.Atoi(d.Id())
 	if err != nil {
 		id = 0
 	}
 
+	rule := attributesToFirewallRule(d)
+	rule.ID = id
+	rule.SectionId = d.Get("sectionid").(int)
+	return rule
+}
+
+func attributesToFirewallRule(d ruleAttributes) firewallRule {
 	var sources *firewall.Sources
 
 	if len(d.Get("source").(*schema.Set).List()) > 0 {
@@ -236,116 +653,248 @@ func tfRuleToFirewallRule(d *schema.ResourceData) firewall.Rule {
 		}
 	}
 
-	var services *firewall.Services
+	var services *firewallServices
 
-	if len(d.Get("service").(*schema.Set).List()) > 0 {
-		services = &firewall.Services{
-			Elements: schemaRuleElementToElement(d.Get("service")),
+	serviceElements := make([]firewallService, 0)
+	for _, elem := range schemaRuleElementToElement(d.Get("service")) {
+		serviceElements = append(serviceElements, firewallService{
+			Name:    elem.Name,
+			Value:   elem.Value,
+			Type:    elem.Type,
+			IsValid: elem.IsValid,
+		})
+	}
+	serviceElements = append(serviceElements, schemaInlineServiceToService(d.Get("inline_service"))...)
+	for _, ethertype := range d.Get("ethertypes").(*schema.Set).List() {
+		serviceElements = append(serviceElements, firewallService{
+			Protocol:     firewallEthertypes[ethertype.(string)],
+			ProtocolName: ethertype.(string),
+		})
+	}
+
+	if len(serviceElements) > 0 {
+		services = &firewallServices{
+			Elements: serviceElements,
 		}
 	}
 
-	return firewall.Rule{
-		Name:       d.Get("name").(string),
-		ID:         id,
-		SectionId:  d.Get("sectionid").(int),
-		Direction:  firewall.Direction(d.Get("direction").(string)),
-		Action:     firewall.Action(d.Get("action").(string)),
-		PacketType: d.Get("packet_type").(string),
-		Disabled:   d.Get("disabled").(bool),
-		Logged:     d.Get("logged").(bool),
-		Notes:      d.Get("description").(string),
-		AppliedToList: &firewall.AppliedToList{
-			Elements: schemaRuleElementToElement(d.Get("applied_to")),
+	return firewallRule{
+		Rule: firewall.Rule{
+			Name:       d.Get("name").(string),
+			Direction:  firewall.Direction(d.Get("direction").(string)),
+			Action:     firewall.Action(d.Get("action").(string)),
+			PacketType: d.Get("packet_type").(string),
+			Disabled:   d.Get("disabled").(bool),
+			Logged:     d.Get("logged").(bool),
+			Notes:      d.Get("description").(string),
+			AppliedToList: &firewall.AppliedToList{
+				Elements: schemaRuleElementToElement(d.Get("applied_to")),
+			},
+			Sources:      sources,
+			Destinations: destinations,
 		},
-		Sources:      sources,
-		Destinations: destinations,
-		Services:     services,
+		Services: services,
 	}
 }
 
-func firewallRuleToTfRule(d *schema.ResourceData, rule *firewall.Rule) {
+func firewallRuleToTfRule(d *schema.ResourceData, rule *firewallRule) {
 	d.SetId(fmt.Sprintf("%d", rule.ID))
-	d.Set("name", rule.Name)
 	d.Set("sectionid", rule.SectionId)
-	d.Set("direction", string(rule.Direction))
-	d.Set("action", string(rule.Action))
-	d.Set("packet_type", rule.PacketType)
-	d.Set("disabled", rule.Disabled)
-	d.Set("logged", rule.Logged)
-	d.Set("description", rule.Notes)
-	d.Set("applied_to", elementToSchemaRuleElement(rule.AppliedToList.Elements))
+
+	for key, value := range firewallRuleToAttributes(rule) {
+		d.Set(key, value)
+	}
+}
+
+func firewallRuleToAttributes(rule *firewallRule) map[string]interface{} {
+	attributes := map[string]interface{}{
+		"name":        rule.Name,
+		"direction":   string(rule.Direction),
+		"action":      string(rule.Action),
+		"packet_type": rule.PacketType,
+		"disabled":    rule.Disabled,
+		"logged":      rule.Logged,
+		"description": rule.Notes,
+		// Elements NSX doesn't have anymore must be cleared from the state.
+		"applied_to":           []map[string]interface{}{},
+		"source":               []map[string]interface{}{},
+		"source_excluded":      []map[string]interface{}{},
+		"destination":          []map[string]interface{}{},
+		"destination_excluded": []map[string]interface{}{},
+		"service":              []map[string]interface{}{},
+		"inline_service":       []map[string]interface{}{},
+		"ethertypes":           []string{},
+	}
+
+	if rule.AppliedToList != nil {
+		attributes["applied_to"] = elementToSchemaRuleElement(rule.AppliedToList.Elements)
+	}
 
 	if rule.Sources != nil && rule.Sources.Elements != nil {
 		if !rule.Sources.Excluded {
-			d.Set("source", elementToSchemaRuleElement(rule.Sources.Elements))
+			attributes["source"] = elementToSchemaRuleElement(rule.Sources.Elements)
 		} else {
-			d.Set("source_excluded", elementToSchemaRuleElement(rule.Sources.Elements))
+			attributes["source_excluded"] = elementToSchemaRuleElement(rule.Sources.Elements)
 		}
 	}
 
 	if rule.Destinations != nil && rule.Destinations.Elements != nil {
 		if !rule.Destinations.Excluded {
-			d.Set("destination", elementToSchemaRuleElement(rule.Destinations.Elements))
+			attributes["destination"] = elementToSchemaRuleElement(rule.Destinations.Elements)
 		} else {
-			d.Set("destination_excluded", elementToSchemaRuleElement(rule.Destinations.Elements))
+			attributes["destination_excluded"] = elementToSchemaRuleElement(rule.Destinations.Elements)
 		}
 	}
 
 	if rule.Services != nil && rule.Services.Elements != nil {
-		d.Set("service", elementToSchemaRuleElement(rule.Services.Elements))
+		var elements []firewall.Element
+		var inlineServices []map[string]interface{}
+		var ethertypes []string
+
+		for _, service := range rule.Services.Elements {
+			// Ethertypes and inline services have no object behind them.
+			if _, ok := firewallEthertypes[strings.ToUpper(service.ProtocolName)]; ok && service.Value == "" {
+				ethertypes = append(ethertypes, strings.ToUpper(service.ProtocolName))
+				continue
+			}
+			if service.Value == "" {
+				inlineServices = append(inlineServices, serviceToSchemaInlineService(service))
+				continue
+			}
+			elements = append(elements, firewall.Element{
+				Name:    service.Name,
+				Value:   service.Value,
+				Type:    service.Type,
+				IsValid: service.IsValid,
+			})
+		}
+
+		attributes["service"] = elementToSchemaRuleElement(elements)
+		if inlineServices != nil {
+			attributes["inline_service"] = inlineServices
+		}
+		if ethertypes != nil {
+			attributes["ethertypes"] = ethertypes
+		}
 	}
+
+	return attributes
 }
 
 func resourceFirewallRuleCreate(d *schema.ResourceData, meta interface{}) error {
 	nsxclient := meta.(*gonsx.NSXClient)
 
-	fConfig := firewall.NewGetFirewallConfig()
-	err := nsxclient.Do(fConfig)
-	if err != nil {
-		return err
-	}
-	d.Set("etag", cleanEtag(fConfig.ResponseHeaders().Get("Etag")))
-
 	rule := tfRuleToFirewallRule(d)
 
-	fRuleCreate := firewall.NewCreateRule(rule.SectionId, cleanEtag(d.Get("etag").(string)), &rule)
-	err = nsxclient.Do(fRuleCreate)
+	layer := firewallRuleLayer(d)
+
+	err := checkFirewallRuleSection(nsxclient, layer, rule.SectionId, d.Get("universal").(bool))
 	if err != nil {
 		return err
 	}
 
-	err = checkerr(fRuleCreate)
+	var fRuleCreate *createFirewallRuleAPI
+	err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		etag, err := getFirewallSectionEtag(nsxclient, layer, rule.SectionId)
+		if err != nil {
+			return nil, err
+		}
+		d.Set("etag", etag)
+
+		fRuleCreate = newCreateFirewallRule(layer, rule.SectionId, etag, &rule)
+		return fRuleCreate, nil
+	})
 	if err != nil {
 		return err
 	}
 
 	d.SetId(fmt.Sprintf("%d", fRuleCreate.GetResponse().ID))
-	return nil
+
+	if operation, anchor := firewallRuleOrder(d); operation != "" {
+		err = moveFirewallRule(nsxclient, layer, rule.SectionId, fRuleCreate.GetResponse().ID, operation, anchor)
+		if err != nil {
+			return err
+		}
+	}
+	return waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutCreate))
 }
 
 func resourceFirewallRuleRead(d *schema.ResourceData, meta interface{}) error {
 	nsxclient := meta.(*gonsx.NSXClient)
 
-	fConfig := firewall.NewGetFirewallConfig()
-	err := nsxclient.Do(fConfig)
+	id, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
-	d.Set("etag", cleanEtag(fConfig.ResponseHeaders().Get("Etag")))
-	id, err := strconv.Atoi(d.Id())
+	fRuleRead := newGetFirewallRule(firewallRuleLayer(d), d.Get("sectionid").(int), id)
+
+	err = nsxclient.Do(fRuleRead)
 	if err != nil {
 		return err
 	}
-	fRuleRead := firewall.NewGetRule(d.Get("sectionid").(int), cleanEtag(d.Get("etag").(string)), id)
 
-	err = nsxclient.Do(fRuleRead)
+	// If the rule has been removed manually, notify Terraform of this fact.
+	if fRuleRead.StatusCode() == http.StatusNotFound {
+		log.Printf("[DEBUG] firewall rule %d not found, removing it from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	err = checkerr(fRuleRead)
 	if err != nil {
 		return err
 	}
+
+	if fRuleRead.GetResponse().ID == 0 {
+		log.Printf("[DEBUG] empty response for firewall rule %d, removing it from state", id)
+		d.SetId("")
+		return nil
+	}
+
 	firewallRuleToTfRule(d, fRuleRead.GetResponse())
+	d.Set("layer", firewallRuleLayer(d))
+
+	// The ETag is the one of the section, as on writes. Rules are evaluated
+	// in order, make sure nobody moved this one.
+	section, etag, err := getFirewallSection(firewallRuleLayer(d), d.Get("sectionid").(int), nsxclient)
+	if err != nil {
+		return err
+	}
+	if section == nil {
+		return nil
+	}
+	d.Set("etag", etag)
+	if operation, anchor := firewallRuleOrder(d); operation != "" {
+		readFirewallRuleOrder(d, section.Rules, id, operation, anchor)
+	}
 	return nil
 }
 
+// readFirewallRuleOrder resets the ordering attribute of a rule when its
+// place in the section no longer matches it, so that a move is planned.
+func readFirewallRuleOrder(d *schema.ResourceData, rules []firewallSectionRule, ruleID int, operation string, anchor int) {
+	index := firewallRuleIndex(rules, ruleID)
+	if index < 0 {
+		return
+	}
+
+	anchorIndex := firewallRuleIndex(rules, anchor)
+	switch operation {
+	case "position":
+		d.Set("position", index+1)
+	case "insert_before":
+		if anchorIndex < 0 || index > anchorIndex {
+			log.Printf("[DEBUG] firewall rule %d is no longer before rule %d", ruleID, anchor)
+			d.Set("insert_before", 0)
+		}
+	case "insert_after":
+		if anchorIndex < 0 || index < anchorIndex {
+			log.Printf("[DEBUG] firewall rule %d is no longer after rule %d", ruleID, anchor)
+			d.Set("insert_after", 0)
+		}
+	}
+}
+
 func resourceFirewallRuleUpdate(d *schema.ResourceData, meta interface{}) error {
 	nsxclient := meta.(*gonsx.NSXClient)
 
@@ -355,35 +904,172 @@ func resourceFirewallRuleUpdate(d *schema.ResourceData, meta interface{}) error
 	}
 
 	rule := tfRuleToFirewallRule(d)
-	fRuleUpdate := firewall.NewUpdateRule(rule.SectionId, cleanEtag(d.Get("etag").(string)), id, rule)
-	err = nsxclient.Do(fRuleUpdate)
+	layer := firewallRuleLayer(d)
+	err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		etag, err := getFirewallSectionEtag(nsxclient, layer, rule.SectionId)
+		if err != nil {
+			return nil, err
+		}
+		d.Set("etag", etag)
+
+		return newUpdateFirewallRule(layer, rule.SectionId, etag, id, rule), nil
+	})
 	if err != nil {
 		return err
 	}
-	return nil
+
+	if d.HasChange("insert_before") || d.HasChange("insert_after") || d.HasChange("position") {
+		if operation, anchor := firewallRuleOrder(d); operation != "" {
+			err = moveFirewallRule(nsxclient, layer, rule.SectionId, id, operation, anchor)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutUpdate))
 }
 
 func resourceFirewallRuleDelete(d *schema.ResourceData, meta interface{}) error {
 	nsxclient := meta.(*gonsx.NSXClient)
 
-	fConfig := firewall.NewGetFirewallConfig()
-	err := nsxclient.Do(fConfig)
+	id, err := strconv.Atoi(d.Id())
 	if err != nil {
 		return err
 	}
-	etag := cleanEtag(fConfig.ResponseHeaders().Get("Etag"))
+	sectionID := d.Get("sectionid").(int)
+	layer := firewallRuleLayer(d)
 
-	id, err := strconv.Atoi(d.Id())
-	if err != nil {
+	var fRuleDelete *deleteFirewallRuleAPI
+	err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		etag, err := getFirewallSectionEtag(nsxclient, layer, sectionID)
+		if err != nil {
+			return nil, err
+		}
+
+		fRuleDelete = newDeleteFirewallRule(layer, sectionID, etag, id)
+		return fRuleDelete, nil
+	})
+	// Deleting a rule that is already gone is not an error.
+	if err != nil && (fRuleDelete == nil || fRuleDelete.StatusCode() != http.StatusNotFound) {
 		return err
 	}
+	return waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutDelete))
+}
+
+// firewallRuleOrder returns the ordering requested for a rule, operation is
+// empty when the rule can be anywhere in its section.
+func firewallRuleOrder(d *schema.ResourceData) (string, int) {
+	if v, ok := d.GetOk("position"); ok {
+		return "position", v.(int)
+	}
+	if v, ok := d.GetOk("insert_before"); ok {
+		return "insert_before", v.(int)
+	}
+	if v, ok := d.GetOk("insert_after"); ok {
+		return "insert_after", v.(int)
+	}
+	return "", 0
+}
+
+func firewallRuleIndex(rules []firewallSectionRule, ruleID int) int {
+	for i, rule := range rules {
+		if rule.ID == ruleID {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveFirewallRule moves a rule within its section. The API has no call for
+// that, so the section is sent back with its rules reordered.
+func moveFirewallRule(nsxclient *gonsx.NSXClient, sectionType string, sectionID, ruleID int, operation string, anchor int) error {
+	return firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		section, etag, err := getFirewallSection(sectionType, sectionID, nsxclient)
+		if err != nil {
+			return nil, err
+		}
+		if section == nil {
+			return nil, fmt.Errorf("firewall section %d not found", sectionID)
+		}
+
+		rules, moved, err := reorderFirewallRules(section.Rules, ruleID, operation, anchor)
+		if err != nil || !moved {
+			return nil, err
+		}
+		section.Rules = rules
+
+		log.Printf("[DEBUG] Moving firewall rule %d of section %d: %s %d", ruleID, sectionID, operation, anchor)
+		return newUpdateFirewallSection(etag, section), nil
+	})
+}
+
+// reorderFirewallRules returns the rules of a section with one of them moved
+// according to operation and anchor, and whether it actually moved.
+func reorderFirewallRules(sectionRules []firewallSectionRule, ruleID int, operation string, anchor int) ([]firewallSectionRule, bool, error) {
+	index := firewallRuleIndex(sectionRules, ruleID)
+	if index < 0 {
+		return nil, false, fmt.Errorf("firewall rule %d not found in its section", ruleID)
+	}
+
+	others := make([]firewallSectionRule, 0, len(sectionRules)-1)
+	others = append(others, sectionRules[:index]...)
+	others = append(others, sectionRules[index+1:]...)
+
+	var target int
+	switch operation {
+	case "position":
+		// A position past the end would be stored back as the last one and
+		// planned again on every run.
+		if anchor > len(sectionRules) {
+			return nil, false, fmt.Errorf("position %d of firewall rule %d is past the end of its section, which has %d rules",
+				anchor, ruleID, len(sectionRules))
+		}
+		target = anchor - 1
+	case "insert_before", "insert_after":
+		target = firewallRuleIndex(others, anchor)
+		if target < 0 {
+			return nil, false, fmt.Errorf("firewall rule %d not found in the section of rule %d", anchor, ruleID)
+		}
+		if operation == "insert_after" {
+			target++
+		}
+	default:
+		return nil, false, fmt.Errorf("unknown firewall rule operation %s", operation)
+	}
 
-	fRuleDelete := firewall.NewDeleteRule(d.Get("sectionid").(int), etag, id)
-	err = nsxclient.Do(fRuleDelete)
+	if target == index {
+		return sectionRules, false, nil
+	}
+
+	rules := make([]firewallSectionRule, 0, len(sectionRules))
+	rules = append(rules, others[:target]...)
+	rules = append(rules, sectionRules[index])
+	rules = append(rules, others[target:]...)
+	return rules, true, nil
+}
+
+// getFirewallEtag returns the current ETag of the whole firewall
+// configuration, it must be sent back on every write.
+func getFirewallEtag(nsxclient *gonsx.NSXClient) (string, error) {
+	fConfig := firewall.NewGetFirewallConfig()
+	err := nsxclient.Do(fConfig)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return cleanEtag(fConfig.ResponseHeaders().Get("Etag")), nil
+}
+
+// getFirewallSectionEtag returns the current ETag of a section, rules are
+// written against it.
+func getFirewallSectionEtag(nsxclient *gonsx.NSXClient, sectionType string, sectionID int) (string, error) {
+	section, etag, err := getFirewallSection(sectionType, sectionID, nsxclient)
+	if err != nil {
+		return "", err
+	}
+	if section == nil {
+		return "", fmt.Errorf("firewall section %d not found", sectionID)
+	}
+	return etag, nil
 }
 
 func cleanEtag(etag string) string {
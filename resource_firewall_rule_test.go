@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestValidateRuleElementValue(t *testing.T) {
+	cases := []struct {
+		elemType string
+		value    string
+		valid    bool
+	}{
+		{"Ipv4Address", "10.0.0.1", true},
+		{"Ipv4Address", "10.0.0.0/24", true},
+		{"Ipv4Address", "10.0.0.1-10.0.0.10", true},
+		{"Ipv4Address", "10.0.0.256", false},
+		{"Ipv4Address", "2001:db8::1", false},
+		{"Ipv4Address", "10.0.0.1-10.0.0.2-10.0.0.3", false},
+		{"Ipv4Address", "securitygroup-10", false},
+		{"Ipv6Address", "2001:db8::/64", true},
+		{"Ipv6Address", "2001:db8::1-2001:db8::ff", true},
+		{"Ipv6Address", "10.0.0.0/24", false},
+		{"SecurityGroup", "securitygroup-10", true},
+		{"SecurityGroup", "securitygroup-7b3c49a2-5f1e-4c1d-9a3b-2e8f0c6d4a11", true},
+		{"SecurityGroup", "securitygroup-7b3c49a2", false},
+		{"SecurityGroup", "10.0.0.1", false},
+		{"IPSet", "ipset-3", true},
+		{"IPSet", "securitygroup-3", false},
+		{"VirtualWire", "virtualwire-12", true},
+		{"VirtualWire", "universalwire-3", true},
+		{"Edge", "edge-1", true},
+		{"Edge", "edge-", false},
+		{"Application", "application-250", true},
+	}
+
+	for _, c := range cases {
+		err := validateRuleElementValue(c.elemType, c.value)
+		if c.valid && err != nil {
+			t.Errorf("%s %q: unexpected error: %s", c.elemType, c.value, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s %q: expected an error", c.elemType, c.value)
+		}
+	}
+}
+
+func TestValidateUniversalFirewallRule(t *testing.T) {
+	cases := []struct {
+		elemType string
+		value    string
+		valid    bool
+	}{
+		{"SecurityGroup", "securitygroup-7b3c49a2-5f1e-4c1d-9a3b-2e8f0c6d4a11", true},
+		{"SecurityGroup", "securitygroup-10", false},
+		{"IPSet", "ipset-0f6a3c1e-2b4d-4e8f-9a1c-3d5e7f9b1c2d", true},
+		{"VirtualWire", "universalwire-3", true},
+		{"VirtualWire", "virtualwire-12", false},
+		{"Edge", "edge-1", false},
+		{"Ipv4Address", "10.0.0.0/24", true},
+		{"DISTRIBUTED_FIREWALL", "DISTRIBUTED_FIREWALL", true},
+		{"SecurityGroup", unknownRuleElementValue, true},
+	}
+
+	for _, c := range cases {
+		rule := ruleAttributeMap{
+			"source": schema.NewSet(setRuleElement, []interface{}{
+				map[string]interface{}{"type": c.elemType, "value": c.value},
+			}),
+		}
+		err := validateFirewallRule("LAYER3", true, rule, "")
+		if c.valid && err != nil {
+			t.Errorf("%s %q: unexpected error: %s", c.elemType, c.value, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s %q: expected an error", c.elemType, c.value)
+		}
+	}
+}
+
+func TestCanonicalRuleElementValue(t *testing.T) {
+	cases := map[string]string{
+		"10.0.0.1":                      "10.0.0.1",
+		"10.0.0.1/32":                   "10.0.0.1",
+		"10.0.0.0/24":                   "10.0.0.0/24",
+		"10.0.0.1 - 10.0.0.10":          "10.0.0.1-10.0.0.10",
+		"2001:0db8:0000::0001":          "2001:db8::1",
+		"2001:db8::1/128":               "2001:db8::1",
+		"2001:0db8::/64":                "2001:db8::/64",
+		"2001:db8::0001-2001:db8::00ff": "2001:db8::1-2001:db8::ff",
+		"securitygroup-10":              "securitygroup-10",
+		"DISTRIBUTED_FIREWALL":          "DISTRIBUTED_FIREWALL",
+		"10.0.0.1-10.0.0.2-10.0.0.3":    "10.0.0.1-10.0.0.2-10.0.0.3",
+	}
+
+	for value, expected := range cases {
+		if canonical := canonicalRuleElementValue(value); canonical != expected {
+			t.Errorf("%q: expected %q, got %q", value, expected, canonical)
+		}
+	}
+}
+
+// sectionRuleIDs returns the IDs of rules, in order.
+func sectionRuleIDs(rules []firewallSectionRule) []int {
+	ids := make([]int, len(rules))
+	for i, rule := range rules {
+		ids[i] = rule.ID
+	}
+	return ids
+}
+
+func TestReorderFirewallRules(t *testing.T) {
+	section := []firewallSectionRule{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+
+	cases := []struct {
+		ruleID    int
+		operation string
+		anchor    int
+		expected  []int
+		moved     bool
+	}{
+		{3, "position", 1, []int{3, 1, 2, 4}, true},
+		{3, "position", 3, []int{1, 2, 3, 4}, false},
+		{1, "position", 4, []int{2, 3, 4, 1}, true},
+		{4, "insert_before", 2, []int{1, 4, 2, 3}, true},
+		{1, "insert_before", 2, []int{1, 2, 3, 4}, false},
+		{1, "insert_after", 3, []int{2, 3, 1, 4}, true},
+		{4, "insert_after", 3, []int{1, 2, 3, 4}, false},
+	}
+
+	for _, c := range cases {
+		rules, moved, err := reorderFirewallRules(section, c.ruleID, c.operation, c.anchor)
+		if err != nil {
+			t.Errorf("%d %s %d: unexpected error: %s", c.ruleID, c.operation, c.anchor, err)
+			continue
+		}
+		if moved != c.moved || !reflect.DeepEqual(sectionRuleIDs(rules), c.expected) {
+			t.Errorf("%d %s %d: got %v (moved %t), expected %v (moved %t)",
+				c.ruleID, c.operation, c.anchor, sectionRuleIDs(rules), moved, c.expected, c.moved)
+		}
+	}
+
+	if _, _, err := reorderFirewallRules(section, 5, "position", 1); err == nil {
+		t.Error("expected an error for a rule missing from the section")
+	}
+	if _, _, err := reorderFirewallRules(section, 1, "insert_before", 5); err == nil {
+		t.Error("expected an error for an anchor missing from the section")
+	}
+	if _, _, err := reorderFirewallRules(section, 1, "position", 5); err == nil {
+		t.Error("expected an error for a position past the end of the section")
+	}
+}
+
+func TestReadFirewallRuleOrder(t *testing.T) {
+	section := []firewallSectionRule{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	cases := []struct {
+		attribute string
+		value     int
+		ruleID    int
+		expected  int
+	}{
+		// position always follows the rule.
+		{"position", 1, 3, 3},
+		{"position", 2, 2, 2},
+		// insert_before and insert_after are reset when the rule moved
+		// past its anchor, or the anchor is gone.
+		{"insert_before", 3, 1, 3},
+		{"insert_before", 1, 3, 0},
+		{"insert_before", 9, 1, 0},
+		{"insert_after", 1, 2, 1},
+		{"insert_after", 3, 2, 0},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceFirewallRule().Schema, map[string]interface{}{
+			"sectionid": 1,
+			c.attribute: c.value,
+		})
+		readFirewallRuleOrder(d, section, c.ruleID, c.attribute, c.value)
+		if got := d.Get(c.attribute).(int); got != c.expected {
+			t.Errorf("%s %d, rule %d: got %d, expected %d", c.attribute, c.value, c.ruleID, got, c.expected)
+		}
+	}
+}
+
+// roundTripFirewallRule sends the rule configured by raw through its XML
+// form and back, as a create followed by a read would.
+func roundTripFirewallRule(t *testing.T, raw map[string]interface{}) *schema.ResourceData {
+	d := schema.TestResourceDataRaw(t, resourceFirewallRule().Schema, raw)
+	rule := tfRuleToFirewallRule(d)
+
+	body, err := xml.Marshal(&rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := new(firewallRule)
+	if err := xml.Unmarshal(body, read); err != nil {
+		t.Fatal(err)
+	}
+
+	result := schema.TestResourceDataRaw(t, resourceFirewallRule().Schema, map[string]interface{}{"sectionid": 1})
+	firewallRuleToTfRule(result, read)
+	return result
+}
+
+func TestFirewallRuleEthertypesRoundTrip(t *testing.T) {
+	d := roundTripFirewallRule(t, map[string]interface{}{
+		"sectionid":  1,
+		"name":       "arp",
+		"layer":      "LAYER2",
+		"ethertypes": []interface{}{"ARP", "IPV6"},
+	})
+
+	ethertypes := d.Get("ethertypes").(*schema.Set)
+	if ethertypes.Len() != 2 || !ethertypes.Contains("ARP") || !ethertypes.Contains("IPV6") {
+		t.Errorf("got ethertypes %v, expected ARP and IPV6", ethertypes.List())
+	}
+	if inlineServices := d.Get("inline_service").(*schema.Set); inlineServices.Len() != 0 {
+		t.Errorf("ethertypes read back as inline services: %v", inlineServices.List())
+	}
+}
+
+func TestValidateFirewallRuleLayer(t *testing.T) {
+	cases := []struct {
+		layer string
+		raw   map[string]interface{}
+		err   string
+	}{
+		{"LAYER2", map[string]interface{}{"ethertypes": []interface{}{"ARP"}}, ""},
+		{"LAYER3", map[string]interface{}{"ethertypes": []interface{}{"ARP"}}, "ethertypes: only layer 2 rules"},
+		{"LAYER2", map[string]interface{}{"action": "reject"}, "action: reject"},
+		{
+			"LAYER2",
+			map[string]interface{}{"source": []interface{}{map[string]interface{}{"type": "IPSet", "value": "ipset-1"}}},
+			"source: IPSet",
+		},
+		{
+			"LAYER2",
+			map[string]interface{}{"inline_service": []interface{}{map[string]interface{}{"protocol": "TCP", "destination_port": "22"}}},
+			"inline_service: protocol TCP",
+		},
+		{
+			"LAYER2",
+			map[string]interface{}{"applied_to": []interface{}{map[string]interface{}{"type": "Edge", "value": "edge-1"}}},
+			"applied_to: layer 2 rules can't be applied to Edge",
+		},
+		{
+			"LAYER2",
+			map[string]interface{}{"applied_to": []interface{}{map[string]interface{}{"type": "VirtualWire", "value": "virtualwire-1"}}},
+			"",
+		},
+		{
+			"LAYER3",
+			map[string]interface{}{"applied_to": []interface{}{map[string]interface{}{"type": "Edge", "value": "edge-1"}}},
+			"",
+		},
+	}
+
+	for i, c := range cases {
+		c.raw["sectionid"] = 1
+		c.raw["name"] = "rule"
+		c.raw["layer"] = c.layer
+		d := schema.TestResourceDataRaw(t, resourceFirewallRule().Schema, c.raw)
+
+		err := validateFirewallRule(c.layer, false, d, "")
+		switch {
+		case c.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error: %s", i, err)
+		case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+			t.Errorf("case %d: got error %v, expected %q", i, err, c.err)
+		}
+	}
+}
+
+func TestFirewallRuleInlineServiceRoundTrip(t *testing.T) {
+	services := []interface{}{
+		map[string]interface{}{"protocol": "TCP", "destination_port": "443", "source_port": "1024-65535"},
+		map[string]interface{}{"protocol": "UDP", "destination_port": "53,5353"},
+		map[string]interface{}{"protocol": "ICMP", "icmp_type": "echo-request"},
+	}
+
+	d := roundTripFirewallRule(t, map[string]interface{}{
+		"sectionid":      1,
+		"name":           "inline",
+		"inline_service": services,
+		"service":        []interface{}{map[string]interface{}{"type": "Application", "value": "application-250"}},
+	})
+
+	inlineServices := d.Get("inline_service").(*schema.Set)
+	if inlineServices.Len() != len(services) {
+		t.Fatalf("got %d inline services, expected %d: %v", inlineServices.Len(), len(services), inlineServices.List())
+	}
+	for _, service := range services {
+		expected := map[string]interface{}{"destination_port": "", "source_port": "", "icmp_type": ""}
+		for key, value := range service.(map[string]interface{}) {
+			expected[key] = value
+		}
+		if !inlineServices.Contains(expected) {
+			t.Errorf("inline service %v missing from %v", expected, inlineServices.List())
+		}
+	}
+
+	if references := d.Get("service").(*schema.Set); references.Len() != 1 {
+		t.Errorf("got service references %v, expected application-250 only", references.List())
+	}
+}
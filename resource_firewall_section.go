@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+	"github.com/sky-uk/gonsx/api"
+)
+
+func resourceFirewallSection() *schema.Resource {
+
+	return &schema.Resource{
+
+		Create: resourceFirewallSectionCreate,
+		Read:   resourceFirewallSectionRead,
+		Update: resourceFirewallSectionUpdate,
+		Delete: resourceFirewallSectionDelete,
+
+		Timeouts: firewallResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "LAYER3",
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"LAYER3",
+					"LAYER2",
+					"L3REDIRECT",
+				}, false),
+			},
+			"stateless": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"universal": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Create a universal section on the primary manager, synchronised to the secondary managers",
+			},
+			"insert_before": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"insert_after"},
+				Description:   "ID of the section to place this section before, defaults to the top of the firewall",
+			},
+			"insert_after": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"insert_before"},
+				Description:   "ID of the section to place this section after, defaults to the top of the firewall",
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"wait_for_publish": schemaWaitForPublish(),
+		},
+	}
+}
+
+// firewallInsertOperation returns the insert operation and anchor requested
+// by the insert_before and insert_after attributes.
+func firewallInsertOperation(d *schema.ResourceData) (string, int) {
+	if v, ok := d.GetOk("insert_before"); ok {
+		return "insert_before", v.(int)
+	}
+	if v, ok := d.GetOk("insert_after"); ok {
+		return "insert_after", v.(int)
+	}
+	return "insert_top", 0
+}
+
+func getFirewallSection(sectionType string, sectionID int, nsxclient *gonsx.NSXClient) (*firewallSection, string, error) {
+	getAPI := newGetFirewallSection(sectionType, sectionID)
+	err := nsxclient.Do(getAPI)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if getAPI.StatusCode() == http.StatusNotFound {
+		return nil, "", nil
+	}
+
+	err = checkerr(getAPI)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The type is used to build the endpoint of later calls, make sure it is
+	// always set.
+	section := getAPI.GetResponse()
+	if section.Type == "" {
+		section.Type = sectionType
+	}
+
+	return section, cleanEtag(getAPI.ResponseHeaders().Get("Etag")), nil
+}
+
+func resourceFirewallSectionCreate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	section := &firewallSection{
+		Name:      d.Get("name").(string),
+		Type:      d.Get("type").(string),
+		Stateless: d.Get("stateless").(bool),
+	}
+	if d.Get("universal").(bool) {
+		section.ManagedBy = universalScopeID
+	}
+	operation, anchorID := firewallInsertOperation(d)
+
+	var createAPI *createFirewallSectionAPI
+	err := firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		etag, err := getFirewallEtag(nsxclient)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("[DEBUG] newCreateFirewallSection(%s, %d, %s)", operation, anchorID, section.Name)
+		createAPI = newCreateFirewallSection(etag, operation, anchorID, section)
+		return createAPI, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%d", createAPI.GetResponse().ID))
+
+	err = waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+	return resourceFirewallSectionRead(d, meta)
+}
+
+func resourceFirewallSectionRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	section, etag, err := getFirewallSection(d.Get("type").(string), id, nsxclient)
+	if err != nil {
+		return err
+	}
+
+	// If the section has been removed manually, notify Terraform of this fact.
+	if section == nil {
+		log.Printf("[DEBUG] firewall section %d not found, removing it from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("etag", etag)
+	d.Set("name", section.Name)
+	d.Set("stateless", section.Stateless)
+	d.Set("universal", section.ManagedBy == universalScopeID)
+	return nil
+}
+
+func resourceFirewallSectionUpdate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	// The whole section is sent back, including its rules, so they have to
+	// be fetched first.
+	getSection := func() (*firewallSection, string, error) {
+		section, etag, err := getFirewallSection(d.Get("type").(string), id, nsxclient)
+		if err == nil && section == nil {
+			err = fmt.Errorf("firewall section %d not found", id)
+		}
+		return section, etag, err
+	}
+
+	if d.HasChange("name") || d.HasChange("stateless") {
+		err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+			section, etag, err := getSection()
+			if err != nil {
+				return nil, err
+			}
+			section.Name = d.Get("name").(string)
+			section.Stateless = d.Get("stateless").(bool)
+
+			return newUpdateFirewallSection(etag, section), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("insert_before") || d.HasChange("insert_after") {
+		operation, anchorID := firewallInsertOperation(d)
+
+		err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+			section, etag, err := getSection()
+			if err != nil {
+				return nil, err
+			}
+
+			log.Printf("[DEBUG] Moving firewall section %d: %s %d", id, operation, anchorID)
+			return newReviseFirewallSection(etag, operation, anchorID, section), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return err
+	}
+	return resourceFirewallSectionRead(d, meta)
+}
+
+func resourceFirewallSectionDelete(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	err = firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		section, etag, err := getFirewallSection(d.Get("type").(string), id, nsxclient)
+		// If the section has been removed manually, there is nothing left
+		// to do.
+		if err != nil || section == nil {
+			return nil, err
+		}
+
+		return newDeleteFirewallSection(etag, section.Type, id), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+	log.Printf("[DEBUG] firewall section %d deleted.", id)
+	return nil
+}
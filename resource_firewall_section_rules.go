@@ -0,0 +1,333 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/sky-uk/gonsx"
+	"github.com/sky-uk/gonsx/api"
+)
+
+var errFirewallSectionNotFound = errors.New("firewall section not found")
+
+func resourceFirewallSectionRules() *schema.Resource {
+	ruleSchema := firewallRuleSchema()
+	// ConflictsWith can't reference attributes of a list element, the
+	// CustomizeDiff rejects them instead.
+	for _, attribute := range ruleSchema {
+		attribute.ConflictsWith = nil
+	}
+	ruleSchema["key"] = &schema.Schema{
+		Type:         schema.TypeString,
+		Required:     true,
+		ValidateFunc: validation.NoZeroValues,
+		Description:  "Unique key of the rule in the section, it must not change for the rule to keep its NSX ID",
+	}
+
+	return &schema.Resource{
+
+		Create: resourceFirewallSectionRulesCreate,
+		Read:   resourceFirewallSectionRulesRead,
+		Update: resourceFirewallSectionRulesUpdate,
+		Delete: resourceFirewallSectionRulesDelete,
+
+		CustomizeDiff: resourceFirewallSectionRulesCustomizeDiff,
+
+		Timeouts: firewallResourceTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"sectionid": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"layer":            schemaFirewallRuleLayer(),
+			"universal":        schemaFirewallRuleUniversal(),
+			"wait_for_publish": schemaWaitForPublish(),
+			"rule": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Ordered list of the rules of the section, any other rule is removed",
+				Elem: &schema.Resource{
+					Schema: ruleSchema,
+				},
+			},
+			"rule_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "NSX ID of each rule, by key",
+			},
+		},
+	}
+}
+
+func resourceFirewallSectionRulesCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.HasChange("rule") {
+		d.SetNewComputed("rule_ids")
+	}
+
+	rules, _ := d.Get("rule").([]interface{})
+	keys := make(map[string]int)
+	for i, v := range rules {
+		attributes, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf("rule.%d.", i)
+
+		if key, _ := attributes["key"].(string); key != "" && key != unknownRuleElementValue {
+			if first, ok := keys[key]; ok {
+				return fmt.Errorf("%skey: %q is already the key of rule.%d", path, key, first)
+			}
+			keys[key] = i
+		}
+
+		err := validateFirewallRule(d.Get("layer").(string), d.Get("universal").(bool), ruleAttributeMap(attributes), path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSectionRules builds the rules of a section from the configured rules.
+// A rule keeps the NSX ID recorded for its key in ruleIDs so that it is
+// updated in place. A rule without one adopts an unclaimed rule of the
+// section with the same name, as long as that name is used by a single rule
+// on both sides. Every other rule of the section is dropped.
+func buildSectionRules(configured []interface{}, ruleIDs map[string]interface{}, section *firewallSection) ([]firewallSectionRule, error) {
+	existing := make(map[int]*firewallRule)
+	for _, sectionRule := range section.Rules {
+		rule, err := sectionRule.Rule()
+		if err != nil {
+			return nil, err
+		}
+		existing[sectionRule.ID] = rule
+	}
+
+	configuredNames := make(map[string]int)
+	for _, v := range configured {
+		configuredNames[v.(map[string]interface{})["name"].(string)]++
+	}
+
+	ids := make([]int, len(configured))
+	used := make(map[int]bool)
+	for i, v := range configured {
+		key := v.(map[string]interface{})["key"].(string)
+		if id, ok := ruleIDs[key].(int); ok && existing[id] != nil && !used[id] {
+			ids[i] = id
+			used[id] = true
+		}
+	}
+	for i, v := range configured {
+		name := v.(map[string]interface{})["name"].(string)
+		if ids[i] != 0 || configuredNames[name] != 1 {
+			continue
+		}
+		candidates := make([]int, 0)
+		for _, sectionRule := range section.Rules {
+			if !used[sectionRule.ID] && existing[sectionRule.ID].Name == name {
+				candidates = append(candidates, sectionRule.ID)
+			}
+		}
+		if len(candidates) == 1 {
+			ids[i] = candidates[0]
+			used[candidates[0]] = true
+		}
+	}
+
+	rules := make([]firewallSectionRule, 0, len(configured))
+	for i, v := range configured {
+		rule := attributesToFirewallRule(ruleAttributeMap(v.(map[string]interface{})))
+		rule.ID = ids[i]
+		rule.SectionId = section.ID
+		setFirewallRuleDefaults(&rule)
+
+		sectionRule, err := newFirewallSectionRule(&rule)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, sectionRule)
+	}
+
+	return rules, nil
+}
+
+// putSectionRules replaces the rules of the section with the ones returned
+// by build, in a single call, and returns the section written.
+func putSectionRules(d *schema.ResourceData, nsxclient *gonsx.NSXClient, build func(*firewallSection) ([]firewallSectionRule, error)) (*firewallSection, error) {
+	sectionID := d.Get("sectionid").(int)
+
+	var updateAPI *updateFirewallSectionAPI
+	err := firewallWrite(nsxclient, func() (api.NSXApi, error) {
+		section, etag, err := getFirewallSection(d.Get("layer").(string), sectionID, nsxclient)
+		if err != nil {
+			return nil, err
+		}
+		if section == nil {
+			return nil, errFirewallSectionNotFound
+		}
+
+		section.Rules, err = build(section)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("[DEBUG] Replacing the %d rules of firewall section %d", len(section.Rules), sectionID)
+		updateAPI = newUpdateFirewallSection(etag, section)
+		return updateAPI, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	section := updateAPI.GetResponse()
+	if section.ID == 0 {
+		// The section wasn't returned, fetch it.
+		section, _, err = getFirewallSection(d.Get("layer").(string), sectionID, nsxclient)
+		if err == nil && section == nil {
+			err = errFirewallSectionNotFound
+		}
+	}
+	return section, err
+}
+
+// writeSectionRules replaces the rules of the section with the configured
+// ones and records the NSX ID of each rule by key.
+func writeSectionRules(d *schema.ResourceData, nsxclient *gonsx.NSXClient) error {
+	configured := d.Get("rule").([]interface{})
+	// rule_ids is only known after the apply, the IDs recorded so far are
+	// its old value.
+	ruleIDs, _ := d.GetChange("rule_ids")
+
+	section, err := putSectionRules(d, nsxclient, func(section *firewallSection) ([]firewallSectionRule, error) {
+		err := checkFirewallSectionScope(section, d.Get("universal").(bool))
+		if err != nil {
+			return nil, err
+		}
+		return buildSectionRules(configured, ruleIDs.(map[string]interface{}), section)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The rules of the section are stored in the order they were sent.
+	if len(section.Rules) != len(configured) {
+		return fmt.Errorf("firewall section %d has %d rules after the update, %d were sent", section.ID, len(section.Rules), len(configured))
+	}
+	newRuleIDs := make(map[string]interface{})
+	for i, v := range configured {
+		newRuleIDs[v.(map[string]interface{})["key"].(string)] = section.Rules[i].ID
+	}
+	return d.Set("rule_ids", newRuleIDs)
+}
+
+func resourceFirewallSectionRulesCreate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	err := writeSectionRules(d, nsxclient)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%d", d.Get("sectionid").(int)))
+
+	err = waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+	return resourceFirewallSectionRulesRead(d, meta)
+}
+
+func resourceFirewallSectionRulesRead(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return err
+	}
+
+	section, etag, err := getFirewallSection(d.Get("layer").(string), id, nsxclient)
+	if err != nil {
+		return err
+	}
+
+	// If the section has been removed manually, notify Terraform of this fact.
+	if section == nil {
+		d.SetId("")
+		return nil
+	}
+
+	keys := make(map[int]string)
+	for key, ruleID := range d.Get("rule_ids").(map[string]interface{}) {
+		keys[ruleID.(int)] = key
+	}
+
+	// Rules added out of band have no key, they show up in the diff and
+	// are removed by the next apply.
+	rules := make([]map[string]interface{}, len(section.Rules))
+	ruleIDs := make(map[string]interface{})
+	for i, sectionRule := range section.Rules {
+		rule, err := sectionRule.Rule()
+		if err != nil {
+			return err
+		}
+		rules[i] = firewallRuleToAttributes(rule)
+		if key, ok := keys[rule.ID]; ok {
+			rules[i]["key"] = key
+			ruleIDs[key] = rule.ID
+		}
+	}
+
+	d.Set("sectionid", id)
+	d.Set("etag", etag)
+	d.Set("universal", section.ManagedBy == universalScopeID)
+	d.Set("rule_ids", ruleIDs)
+	return d.Set("rule", rules)
+}
+
+func resourceFirewallSectionRulesUpdate(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	if d.HasChange("rule") {
+		err := writeSectionRules(d, nsxclient)
+		if err != nil {
+			return err
+		}
+
+		err = waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+	}
+	return resourceFirewallSectionRulesRead(d, meta)
+}
+
+func resourceFirewallSectionRulesDelete(d *schema.ResourceData, meta interface{}) error {
+	nsxclient := meta.(*gonsx.NSXClient)
+
+	// The section itself belongs to nsx_firewall_section, only empty it.
+	_, err := putSectionRules(d, nsxclient, func(*firewallSection) ([]firewallSectionRule, error) {
+		return []firewallSectionRule{}, nil
+	})
+	if err != nil && err != errFirewallSectionNotFound {
+		return err
+	}
+
+	err = waitForFirewallPublish(d, nsxclient, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/sky-uk/gonsx/api/firewall"
+)
+
+// testSectionRules returns the rule blocks of a nsx_firewall_section_rules
+// configured with the given key/name pairs.
+func testSectionRules(t *testing.T, keyNames ...string) []interface{} {
+	rules := make([]interface{}, 0)
+	for i := 0; i < len(keyNames); i += 2 {
+		rules = append(rules, map[string]interface{}{
+			"key":  keyNames[i],
+			"name": keyNames[i+1],
+		})
+	}
+	d := schema.TestResourceDataRaw(t, resourceFirewallSectionRules().Schema, map[string]interface{}{
+		"sectionid": 1,
+		"rule":      rules,
+	})
+	return d.Get("rule").([]interface{})
+}
+
+// testSection returns a section holding rules of the given id/name pairs.
+func testSection(t *testing.T, idNames ...interface{}) *firewallSection {
+	section := &firewallSection{ID: 1, Type: "LAYER3"}
+	for i := 0; i < len(idNames); i += 2 {
+		sectionRule, err := newFirewallSectionRule(&firewallRule{
+			Rule: firewall.Rule{ID: idNames[i].(int), Name: idNames[i+1].(string)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		section.Rules = append(section.Rules, sectionRule)
+	}
+	return section
+}
+
+func TestBuildSectionRules(t *testing.T) {
+	cases := []struct {
+		description string
+		configured  []interface{}
+		ruleIDs     map[string]interface{}
+		section     *firewallSection
+		expected    []int
+	}{
+		{
+			"rules keep the ID of their key",
+			testSectionRules(t, "a", "web", "b", "db"),
+			map[string]interface{}{"a": 10, "b": 11},
+			testSection(t, 10, "web", 11, "db"),
+			[]int{10, 11},
+		},
+		{
+			"reordered rules keep their ID",
+			testSectionRules(t, "b", "db", "a", "web"),
+			map[string]interface{}{"a": 10, "b": 11},
+			testSection(t, 10, "web", 11, "db"),
+			[]int{11, 10},
+		},
+		{
+			"a rule inserted in the middle is new",
+			testSectionRules(t, "a", "web", "c", "app", "b", "db"),
+			map[string]interface{}{"a": 10, "b": 11},
+			testSection(t, 10, "web", 11, "db"),
+			[]int{10, 0, 11},
+		},
+		{
+			"a renamed rule keeps its ID",
+			testSectionRules(t, "a", "frontend"),
+			map[string]interface{}{"a": 10},
+			testSection(t, 10, "web"),
+			[]int{10},
+		},
+		{
+			"rules added out of band are dropped",
+			testSectionRules(t, "a", "web", "b", "db"),
+			map[string]interface{}{"a": 10, "b": 12},
+			testSection(t, 10, "web", 11, "manual", 12, "db"),
+			[]int{10, 12},
+		},
+		{
+			"IDs of rules removed out of band are not reused",
+			testSectionRules(t, "a", "web"),
+			map[string]interface{}{"a": 10},
+			testSection(t, 11, "db"),
+			[]int{0},
+		},
+		{
+			"a rule without an ID adopts the rule of the same name",
+			testSectionRules(t, "a", "web", "b", "db"),
+			map[string]interface{}{},
+			testSection(t, 11, "db", 10, "web"),
+			[]int{10, 11},
+		},
+		{
+			"rules of the section with the same name are not adopted",
+			testSectionRules(t, "a", "web"),
+			map[string]interface{}{},
+			testSection(t, 10, "web", 11, "web"),
+			[]int{0},
+		},
+		{
+			"configured rules with the same name don't adopt",
+			testSectionRules(t, "a", "web", "b", "web"),
+			map[string]interface{}{},
+			testSection(t, 10, "web"),
+			[]int{0, 0},
+		},
+		{
+			"a rule claimed by its key is not adopted by name",
+			testSectionRules(t, "b", "web", "a", "db"),
+			map[string]interface{}{"a": 10},
+			testSection(t, 10, "web"),
+			[]int{0, 10},
+		},
+	}
+
+	for _, c := range cases {
+		rules, err := buildSectionRules(c.configured, c.ruleIDs, c.section)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.description, err)
+			continue
+		}
+		if ids := sectionRuleIDs(rules); !reflect.DeepEqual(ids, c.expected) {
+			t.Errorf("%s: got rule IDs %v, expected %v", c.description, ids, c.expected)
+		}
+	}
+}
+
+func TestFirewallSectionRulesCustomizeDiff(t *testing.T) {
+	ipv4 := func(value string) []interface{} {
+		return []interface{}{map[string]interface{}{"type": "Ipv4Address", "value": value}}
+	}
+
+	cases := []struct {
+		rules []interface{}
+		err   string
+	}{
+		{
+			[]interface{}{
+				map[string]interface{}{"key": "a", "name": "web"},
+				map[string]interface{}{"key": "b", "name": "db"},
+			},
+			"",
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{"key": "a", "name": "web"},
+				map[string]interface{}{"key": "a", "name": "db"},
+			},
+			`rule.1.key: "a" is already the key of rule.0`,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{"key": "a", "name": "web"},
+				map[string]interface{}{
+					"key":             "b",
+					"name":            "db",
+					"source":          ipv4("10.0.0.1"),
+					"source_excluded": ipv4("10.0.0.2"),
+				},
+			},
+			"rule.1.source_excluded: conflicts with rule.1.source",
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"key":                  "a",
+					"name":                 "web",
+					"destination":          ipv4("10.0.0.1"),
+					"destination_excluded": ipv4("10.0.0.2"),
+				},
+			},
+			"rule.0.destination_excluded: conflicts with rule.0.destination",
+		},
+	}
+
+	for i, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"sectionid": 1,
+			"rule":      c.rules,
+		})
+		_, err := resourceFirewallSectionRules().Diff(nil, config, nil)
+		switch {
+		case c.err == "" && err != nil:
+			t.Errorf("case %d: unexpected error: %s", i, err)
+		case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+			t.Errorf("case %d: got error %v, expected %q", i, err, c.err)
+		}
+	}
+}
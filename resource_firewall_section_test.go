@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/sky-uk/gonsx"
+)
+
+func TestAccNSXFirewallSectionBasic(t *testing.T) {
+
+	randomInt := acctest.RandInt()
+	sectionName := fmt.Sprintf("acctest-nsx-firewall-section-%d", randomInt)
+	updateSectionName := fmt.Sprintf("acctest-nsx-firewall-section-%d-update", randomInt)
+	testResourceName := "nsx_firewall_section.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccNSXFirewallSectionCheckDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNSXFirewallSectionTemplate(sectionName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccNSXFirewallSectionExists(sectionName, testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "name", sectionName),
+					resource.TestCheckResourceAttr(testResourceName, "type", "LAYER3"),
+					resource.TestCheckResourceAttr(testResourceName, "stateless", "false"),
+					resource.TestCheckResourceAttr("nsx_firewall_rule.acctest", "name", sectionName),
+				),
+			},
+			{
+				Config: testAccNSXFirewallSectionTemplate(updateSectionName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccNSXFirewallSectionExists(updateSectionName, testResourceName),
+					resource.TestCheckResourceAttr(testResourceName, "name", updateSectionName),
+				),
+			},
+		},
+	})
+}
+
+func testAccNSXFirewallSectionExists(name, resourceName string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+
+		nsxClient := testAccProvider.Meta().(*gonsx.NSXClient)
+
+		rs, ok := state.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("NSX firewall section resource %s not found in resources", resourceName)
+		}
+
+		resourceID, err := strconv.Atoi(rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("NSX firewall section resource ID not set in resources ")
+		}
+
+		getAPI := newGetFirewallSection(rs.Primary.Attributes["type"], resourceID)
+		err = nsxClient.Do(getAPI)
+		if err != nil {
+			return fmt.Errorf("Error while retrieving firewall section ID %d. Error: %v", resourceID, err)
+		}
+		if getAPI.StatusCode() != http.StatusOK {
+			return fmt.Errorf("Error while checking if firewall section %d exists. HTTP return code was %d", resourceID, getAPI.StatusCode())
+		}
+
+		if name == getAPI.GetResponse().Name {
+			return nil
+		}
+		return fmt.Errorf("NSX firewall section %s wasn't found", name)
+	}
+}
+
+func testAccNSXFirewallSectionCheckDestroy(state *terraform.State) error {
+
+	nsxClient := testAccProvider.Meta().(*gonsx.NSXClient)
+
+	for _, rs := range state.RootModule().Resources {
+
+		if rs.Type != "nsx_firewall_section" {
+			continue
+		}
+
+		resourceID, err := strconv.Atoi(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+
+		getAPI := newGetFirewallSection(rs.Primary.Attributes["type"], resourceID)
+		err = nsxClient.Do(getAPI)
+		if err != nil {
+			return fmt.Errorf("Error while retrieving firewall section ID %d. Error: %v", resourceID, err)
+		}
+
+		if getAPI.StatusCode() != http.StatusNotFound {
+			return fmt.Errorf("NSX firewall section %d still exists", resourceID)
+		}
+	}
+	return nil
+}
+
+func testAccNSXFirewallSectionTemplate(name string) string {
+	return fmt.Sprintf(`
+resource "nsx_firewall_section" "acctest" {
+name = "%s"
+}
+
+resource "nsx_firewall_rule" "acctest" {
+name = "%s"
+sectionid = nsx_firewall_section.acctest.id
+action = "deny"
+}`, name, name)
+}
@@ -4,25 +4,34 @@ import (
 	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/sky-uk/gonsx"
 	"github.com/sky-uk/gonsx/api/securitygroup"
 	"log"
+	"net/http"
+	"regexp"
+	"strings"
 )
 
-func getSingleSecurityGroup(scopeID, name string, nsxclient *gonsx.NSXClient) (*securitygroup.SecurityGroup, error) {
-	getAllAPI := securitygroup.NewGetAll(scopeID)
-	err := nsxclient.Do(getAllAPI)
+// getSecurityGroup returns the security group with the given ID, or nil when
+// it doesn't exist.
+func getSecurityGroup(id string, nsxclient *gonsx.NSXClient) (*securityGroup, error) {
+	getAPI := newGetSecurityGroup(id)
+	err := nsxclient.Do(getAPI)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if getAllAPI.StatusCode() != 200 {
-		return nil, fmt.Errorf("Status code: %d, Response: %s", getAllAPI.StatusCode(), getAllAPI.ResponseObject())
+	if getAPI.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if getAPI.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("Status code: %d, Response: %s", getAPI.StatusCode(), getAPI.RawResponse())
 	}
 
-	securityGroup := getAllAPI.GetResponse().FilterByName(name)
-	return securityGroup, nil
+	return getAPI.GetResponse(), nil
 }
 
 func resourceSecurityGroup() *schema.Resource {
@@ -32,12 +41,19 @@ func resourceSecurityGroup() *schema.Resource {
 		Update: resourceSecurityGroupUpdate,
 		Delete: resourceSecurityGroupDelete,
 
+		Importer: &schema.ResourceImporter{
+			State: resourceSecurityGroupImport,
+		},
+
+		CustomizeDiff: resourceSecurityGroupCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"scopeid": &schema.Schema{
-				Type:     schema.TypeString,
-				Optional: true,
-				Default:  "globalroot-0",
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     globalScopeID,
+				ForceNew:    true,
+				Description: "Scope of the group, universalroot-0 for a universal group created on the primary manager",
 			},
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -47,10 +63,38 @@ func resourceSecurityGroup() *schema.Resource {
 				Type:     schema.TypeBool,
 				Computed: true,
 			},
+			"adopted": &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the group already existed and was adopted instead of created",
+			},
 			"no_delete": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
 			},
+			"adopt_existing": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "adopt",
+				ValidateFunc: validation.StringInSlice([]string{
+					"adopt",
+					"error",
+					"adopt_and_manage",
+				}, false),
+				Description: "What to do when a group of the same name already exists: adopt it and never delete it, fail, or adopt it and delete it on destroy",
+			},
+			"include_members": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the objects statically included in the group, e.g. vm-42, ipset-3, virtualwire-7 or securitygroup-12",
+			},
+			"exclude_members": &schema.Schema{
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the objects always excluded from the group",
+			},
 			"dynamic_membership": &schema.Schema{
 				Type:     schema.TypeList,
 				Optional: true,
@@ -96,16 +140,14 @@ func resourceSecurityGroup() *schema.Resource {
 }
 
 func findSecurityGroup(name, scopeid string, nsxclient *gonsx.NSXClient) (securitygroup.SecurityGroup, error) {
-	getAllAPI := securitygroup.NewGetAll(scopeid)
+	getAllAPI := newGetAllSecurityGroups(scopeid)
 
 	err := nsxclient.Do(getAllAPI)
 	if err != nil {
 		return securitygroup.SecurityGroup{}, err
 	}
-	for _, secGroup := range getAllAPI.GetResponse().SecurityGroups {
-		if secGroup.Name == name {
-			return secGroup, nil
-		}
+	if secGroup := getAllAPI.GetResponse().FilterByName(name); secGroup != nil {
+		return secGroup.SecurityGroup, nil
 	}
 	return securitygroup.SecurityGroup{}, nil
 }
@@ -126,16 +168,29 @@ func validateSecurityGroupRulesOperator(v interface{}, k string) (ws []string, e
 	return
 }
 
+// securityGroupStringCriteria are the criteria of the keys matching a string.
+var securityGroupStringCriteria = []string{"=", "!=", "contains", "starts_with", "ends_with", "similar_to"}
+
+// securityGroupRuleCriteria maps each key of a dynamic membership rule to
+// the criteria it accepts. The guest OS of a VM is matched by its full name,
+// e.g. "Microsoft Windows Server 2016 (64-bit)", or by the family, e.g.
+// windowsGuest, and ID, e.g. windows9Server64Guest, vCenter gives it.
+var securityGroupRuleCriteria = map[string][]string{
+	"VM.SECURITY_TAG":       securityGroupStringCriteria,
+	"VM.GUEST_OS_FULL_NAME": securityGroupStringCriteria,
+	"VM.GUEST_OS_FAMILY":    {"=", "!="},
+	"VM.GUEST_OS_ID":        {"=", "!="},
+	"VM.GUEST_HOST_NAME":    securityGroupStringCriteria,
+	"VM.NAME":               securityGroupStringCriteria,
+	"COMPUTER_NAME":         securityGroupStringCriteria,
+	"COMPUTER_OS_NAME":      securityGroupStringCriteria,
+	"USER_ID":               {"=", "!="},
+	"ENTITY":                {"belongs_to"},
+}
+
 func validateSecurityGroupRuleKey(v interface{}, k string) (ws []string, errors []error) {
 	value := v.(string)
-	keyTypes := map[string]bool{
-		"VM.SECURITY_TAG":       true,
-		"VM.GUEST_OS_FULL_NAME": true,
-		"VM.GUEST_HOST_NAME":    true,
-		"VM.NAME":               true,
-		"ENTITY":                true,
-	}
-	if !keyTypes[value] {
+	if _, ok := securityGroupRuleCriteria[value]; !ok {
 		errors = append(errors, fmt.Errorf("%q must be a valid key, check documentation for acceptable values", k))
 	}
 	return
@@ -143,21 +198,78 @@ func validateSecurityGroupRuleKey(v interface{}, k string) (ws []string, errors
 
 func validateSecurityGroupRuleCriteria(v interface{}, k string) (ws []string, errors []error) {
 	value := v.(string)
-	possibleCriteriaValues := map[string]bool{
-		"belongs_to":  true,
-		"starts_with": true,
-		"ends_with":   true,
-		"=":           true,
-		"!=":          true,
-		"contains":    true,
-		"similar_to":  true,
-	}
-	if !possibleCriteriaValues[value] {
-		errors = append(errors, fmt.Errorf("%q must be a valid criteria value, check documentation for acceptable values", k))
+	for _, criteriaList := range securityGroupRuleCriteria {
+		for _, criteria := range criteriaList {
+			if value == criteria {
+				return
+			}
+		}
 	}
+	errors = append(errors, fmt.Errorf("%q must be a valid criteria value, check documentation for acceptable values", k))
 	return
 }
 
+// validateSecurityGroupRule checks that criteria can be used with key. The
+// value of an ENTITY rule is left to NSX, it can be the ID of many kinds of
+// objects.
+func validateSecurityGroupRule(key, criteria string) error {
+	valid := false
+	for _, keyCriteria := range securityGroupRuleCriteria[key] {
+		valid = valid || keyCriteria == criteria
+	}
+	if !valid {
+		return fmt.Errorf("criteria %q can't be used with key %s, expected one of %s",
+			criteria, key, strings.Join(securityGroupRuleCriteria[key], ", "))
+	}
+	return nil
+}
+
+func resourceSecurityGroupCustomizeDiff(d *schema.ResourceDiff, m interface{}) error {
+	universal := d.Get("scopeid").(string) == universalScopeID
+	if universal {
+		for _, key := range []string{"include_members", "exclude_members"} {
+			members, ok := d.Get(key).(*schema.Set)
+			if !ok {
+				continue
+			}
+			for _, member := range members.List() {
+				if err := validateUniversalReference(member.(string)); err != nil {
+					return fmt.Errorf("%s: %s", key, err)
+				}
+			}
+		}
+	}
+
+	dynamicSets, _ := d.Get("dynamic_membership").([]interface{})
+	for i, v := range dynamicSets {
+		dynamicSet, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rules, ok := dynamicSet["rules"].(*schema.Set)
+		if !ok {
+			continue
+		}
+		for _, rule := range getListOfStructs(rules) {
+			key, _ := rule["key"].(string)
+			criteria, _ := rule["criteria"].(string)
+			value, _ := rule["value"].(string)
+			if key == "" || criteria == "" {
+				continue
+			}
+			if err := validateSecurityGroupRule(key, criteria); err != nil {
+				return fmt.Errorf("dynamic_membership.%d.rules: %s", i, err)
+			}
+			if universal && key == "ENTITY" {
+				if err := validateUniversalReference(value); err != nil {
+					return fmt.Errorf("dynamic_membership.%d.rules: %s", i, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func buildDynamicMemberDefinition(m interface{}) (*securitygroup.DynamicMemberDefinition, error) {
 	newDynamicMemberDefinition := &securitygroup.DynamicMemberDefinition{
 		DynamicSet: make([]securitygroup.DynamicSet, 0),
@@ -191,6 +303,59 @@ func buildDynamicRules(m interface{}, rulesOperator string) ([]securitygroup.Dyn
 	return newDynamicCriterion, nil
 }
 
+func expandSecurityGroupMembers(v interface{}) []securityGroupMember {
+	members := make([]securityGroupMember, 0)
+	for _, memberID := range v.(*schema.Set).List() {
+		members = append(members, securityGroupMember{ObjectID: memberID.(string)})
+	}
+	return members
+}
+
+func securityGroupMemberIDs(members []securityGroupMember) []string {
+	memberIDs := make([]string, len(members))
+	for i, member := range members {
+		memberIDs[i] = member.ObjectID
+	}
+	return memberIDs
+}
+
+// updateSecurityGroupIncludes adds and removes the included members of a
+// group one by one, so that the rest of the group is left untouched.
+func updateSecurityGroupIncludes(nsxclient *gonsx.NSXClient, id string, current []securityGroupMember, desired *schema.Set) error {
+	currentSet := schema.NewSet(schema.HashString, nil)
+	for _, memberID := range securityGroupMemberIDs(current) {
+		currentSet.Add(memberID)
+	}
+
+	for _, memberID := range currentSet.Difference(desired).List() {
+		log.Printf("[DEBUG] Removing %s from security group %s", memberID, id)
+		removeAPI := newRemoveSecurityGroupMember(id, memberID.(string))
+		err := nsxclient.Do(removeAPI)
+		if err != nil {
+			return err
+		}
+		err = checkerr(removeAPI)
+		if err != nil {
+			return fmt.Errorf("Error removing %s from security group %s: %s", memberID, id, err)
+		}
+	}
+
+	for _, memberID := range desired.Difference(currentSet).List() {
+		log.Printf("[DEBUG] Adding %s to security group %s", memberID, id)
+		addAPI := newAddSecurityGroupMember(id, memberID.(string))
+		err := nsxclient.Do(addAPI)
+		if err != nil {
+			return err
+		}
+		err = checkerr(addAPI)
+		if err != nil {
+			return fmt.Errorf("Error adding %s to security group %s: %s", memberID, id, err)
+		}
+	}
+
+	return nil
+}
+
 func resourceSecurityGroupCreate(d *schema.ResourceData, m interface{}) error {
 
 	nsxclient := m.(*gonsx.NSXClient)
@@ -226,16 +391,31 @@ func resourceSecurityGroupCreate(d *schema.ResourceData, m interface{}) error {
 	}
 
 	if secGroupExists.ObjectID != "" {
+		adoptExisting := d.Get("adopt_existing").(string)
+		if adoptExisting == "error" {
+			return fmt.Errorf("Security group %s already exists in scope %s: %s", name, scopeid, secGroupExists.ObjectID)
+		}
+
 		d.SetId(secGroupExists.ObjectID)
-		d.Set("existing", true)
-		if _, ok := d.GetOk("dynamic_membership"); ok {
+		d.Set("adopted", true)
+		d.Set("existing", adoptedSecurityGroupKept(adoptExisting))
+		_, hasDynamicMembership := d.GetOk("dynamic_membership")
+		_, hasIncludeMembers := d.GetOk("include_members")
+		_, hasExcludeMembers := d.GetOk("exclude_members")
+		if hasDynamicMembership || hasIncludeMembers || hasExcludeMembers {
 			return resourceSecurityGroupUpdate(d, m)
 		}
 		return nil
 	}
 
-	log.Printf("[DEBUG] securitygroup.NewCreate(%s, %s, %v", scopeid, name, &dynamicMemberDefinition)
-	createAPI := securitygroup.NewCreate(scopeid, name, dynamicMemberDefinition)
+	newSecurityGroup := new(securityGroup)
+	newSecurityGroup.Name = name
+	newSecurityGroup.DynamicMemberDefinition = dynamicMemberDefinition
+	newSecurityGroup.Members = expandSecurityGroupMembers(d.Get("include_members"))
+	newSecurityGroup.ExcludeMembers = expandSecurityGroupMembers(d.Get("exclude_members"))
+
+	log.Printf("[DEBUG] newCreateSecurityGroup(%s, %s, %v", scopeid, name, &dynamicMemberDefinition)
+	createAPI := newCreateSecurityGroup(scopeid, newSecurityGroup)
 	err = nsxclient.Do(createAPI)
 
 	if err != nil {
@@ -247,101 +427,132 @@ func resourceSecurityGroupCreate(d *schema.ResourceData, m interface{}) error {
 	}
 
 	d.SetId(createAPI.GetResponse())
+	d.Set("adopted", false)
 	return resourceSecurityGroupRead(d, m)
 }
 
 func resourceSecurityGroupRead(d *schema.ResourceData, m interface{}) error {
 	nsxclient := m.(*gonsx.NSXClient)
-	var dynamicMembership *securitygroup.DynamicMemberDefinition
-	var scopeid, name string
-	var err error
-
-	if v, ok := d.GetOk("scopeid"); ok {
-		scopeid = v.(string)
-	} else {
-		return errors.New("scopeid argument is required")
-	}
-
-	if v, ok := d.GetOk("name"); ok {
-		name = v.(string)
-	} else {
-		return errors.New("name argument is required")
-	}
-
-	if v, ok := d.GetOk("dynamic_membership"); ok {
-		dynamicMembership, err = buildDynamicMemberDefinition(v)
-		if err != nil {
-			return err
-		}
-	} else {
-		dynamicMembership = &securitygroup.DynamicMemberDefinition{
-			DynamicSet: make([]securitygroup.DynamicSet, 0),
-		}
-	}
 
-	// See if we can find our specifically named resource within the list of
-	// resources associated with the scopeid.
-	log.Printf("[DEBUG] api.GetResponse().FilterByName(\"%s\").ObjectID", name)
-	securityGroupObject, err := getSingleSecurityGroup(scopeid, name, nsxclient)
+	log.Printf("[DEBUG] newGetSecurityGroup(%s)", d.Id())
+	securityGroupObject, err := getSecurityGroup(d.Id(), nsxclient)
 	if err != nil {
 		return err
 	}
 
-	id := securityGroupObject.ObjectID
-	log.Printf("[DEBUG] id := %s", id)
-
 	// If the resource has been removed manually, notify Terraform of this fact.
-	if id == "" {
+	if securityGroupObject == nil {
 		d.SetId("")
 		return nil
 	}
 
-	if securityGroupObject.DynamicMemberDefinition == nil {
-		return nil
+	d.Set("name", securityGroupObject.Name)
+	if securityGroupObject.Scope != nil {
+		d.Set("scopeid", securityGroupObject.Scope.ID)
 	}
+	d.Set("include_members", securityGroupMemberIDs(securityGroupObject.Members))
+	d.Set("exclude_members", securityGroupMemberIDs(securityGroupObject.ExcludeMembers))
+
 	log.Printf("[DEBUG] dynamicMembership := %v", securityGroupObject.DynamicMemberDefinition)
-	for idx, remoteDynamicSet := range securityGroupObject.DynamicMemberDefinition.DynamicSet {
-		dynamicMembership.DynamicSet[idx].Operator = remoteDynamicSet.Operator
-		readDynamicCriteria(dynamicMembership.DynamicSet[idx].DynamicCriteria,
-			remoteDynamicSet.DynamicCriteria)
-	}
-	d.Set("dynamic_membership", dynamicMembership)
-	return nil
+	return d.Set("dynamic_membership", flattenDynamicMemberDefinition(securityGroupObject.DynamicMemberDefinition))
 }
 
-func readDynamicCriteria(localCriteriaList, remoteCriteriaList []securitygroup.DynamicCriteria) {
-	for _, localRule := range localCriteriaList {
-		for _, remoteRule := range remoteCriteriaList {
-			if localRule.Value == remoteRule.Value {
-				localRule.Criteria = remoteRule.Criteria
-				localRule.Key = remoteRule.Key
-				break
+// flattenDynamicMemberDefinition is the reverse of
+// buildDynamicMemberDefinition, the operator of the criteria of a set is
+// the rules_operator of the set.
+func flattenDynamicMemberDefinition(dynamicMemberDefinition *securitygroup.DynamicMemberDefinition) []map[string]interface{} {
+	dynamicSetList := make([]map[string]interface{}, 0)
+	if dynamicMemberDefinition == nil {
+		return dynamicSetList
+	}
+
+	for _, dynamicSet := range dynamicMemberDefinition.DynamicSet {
+		rulesOperator := ""
+		rules := make([]map[string]interface{}, len(dynamicSet.DynamicCriteria))
+		for index, dynamicCriteria := range dynamicSet.DynamicCriteria {
+			rulesOperator = dynamicCriteria.Operator
+			rules[index] = map[string]interface{}{
+				"key":      dynamicCriteria.Key,
+				"value":    dynamicCriteria.Value,
+				"criteria": dynamicCriteria.Criteria,
 			}
 		}
+
+		dynamicSetList = append(dynamicSetList, map[string]interface{}{
+			"set_operator":   dynamicSet.Operator,
+			"rules_operator": rulesOperator,
+			"rules":          rules,
+		})
+	}
+	return dynamicSetList
+}
+
+// adoptedSecurityGroupKept tells if an adopted group is left behind on
+// destroy, only adopt_and_manage hands it over to Terraform.
+func adoptedSecurityGroupKept(adoptExisting string) bool {
+	return adoptExisting != "adopt_and_manage"
+}
+
+var securityGroupIDPattern = regexp.MustCompile(`^securitygroup-(\d+|` + universalIDSuffix + `)$`)
+
+// resourceSecurityGroupImport accepts [scopeid/]securitygroup-123,
+// universalroot-0/securitygroup-<uuid> or [scopeid/]name, the scope defaults
+// to globalroot-0.
+func resourceSecurityGroupImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	nsxclient := m.(*gonsx.NSXClient)
+
+	scopeid, ref := globalScopeID, d.Id()
+	if parts := strings.SplitN(d.Id(), "/", 2); len(parts) == 2 {
+		scopeid, ref = parts[0], parts[1]
+	}
+
+	id := ref
+	if !securityGroupIDPattern.MatchString(ref) {
+		secGroup, err := findSecurityGroup(ref, scopeid, nsxclient)
+		if err != nil {
+			return nil, err
+		}
+		if secGroup.ObjectID == "" {
+			return nil, fmt.Errorf("Security group %s not found in scope %s", ref, scopeid)
+		}
+		id = secGroup.ObjectID
 	}
+
+	d.SetId(id)
+	d.Set("scopeid", scopeid)
+	d.Set("existing", false)
+	d.Set("adopted", false)
+	d.Set("adopt_existing", "adopt")
+	return []*schema.ResourceData{d}, nil
 }
 
 func resourceSecurityGroupUpdate(d *schema.ResourceData, m interface{}) error {
 
-	var scopeid string
 	var dynamicMembership *securitygroup.DynamicMemberDefinition
-	var err error
 
 	nsxclient := m.(*gonsx.NSXClient)
 	hasChanges := false
+	id := d.Id()
 
-	if v, ok := d.GetOk("scopeid"); ok {
-		scopeid = v.(string)
-	} else {
-		return errors.New("scopeid argument is required")
+	// Switching an adopted group to adopt_and_manage hands it over to
+	// Terraform, including its deletion, switching it back leaves it behind
+	// again. Groups adopted before adopted was recorded only have existing.
+	if d.HasChange("adopt_existing") {
+		adopted := d.Get("adopted").(bool) || d.Get("existing").(bool)
+		d.Set("adopted", adopted)
+		d.Set("existing", adopted && adoptedSecurityGroupKept(d.Get("adopt_existing").(string)))
 	}
 
-	log.Printf("[DEBUG] securitygroup.NewGetAll(%s)", scopeid)
+	log.Printf("[DEBUG] newGetSecurityGroup(%s)", id)
 	oldName, newName := d.GetChange("name")
-	securityGroupObject, err := getSingleSecurityGroup(scopeid, oldName.(string), nsxclient) //nolint, maybe a reason to not trigger error
-	id := securityGroupObject.ObjectID
+	securityGroupObject, err := getSecurityGroup(id, nsxclient)
+	if err != nil {
+		return err
+	}
+	if securityGroupObject == nil {
+		return fmt.Errorf("Security group %s not found", id)
+	}
 
-	// TODO: change attributes other than name. Requires changes in gonsx.
 	if d.HasChange("name") {
 		hasChanges = true
 		securityGroupObject.Name = newName.(string)
@@ -359,11 +570,31 @@ func resourceSecurityGroupUpdate(d *schema.ResourceData, m interface{}) error {
 		securityGroupObject.DynamicMemberDefinition = dynamicMembership
 	}
 
+	if d.HasChange("exclude_members") {
+		hasChanges = true
+		securityGroupObject.ExcludeMembers = expandSecurityGroupMembers(d.Get("exclude_members"))
+	}
+
+	// NSX only adds and removes included members one by one, the name, the
+	// dynamic membership and the excluded members can only be changed by
+	// sending the whole group. The included members are sent as they
+	// currently are, they are updated one by one below.
 	if hasChanges {
-		updateAPI := securitygroup.NewUpdate(id, securityGroupObject)
+		updateAPI := newUpdateSecurityGroup(securityGroupObject)
 		err = nsxclient.Do(updateAPI)
 		if err != nil {
-			log.Printf("[DEBUG] Error updating security group: %s", err)
+			return fmt.Errorf("Error updating security group %s: %s", id, err)
+		}
+		err = checkerr(updateAPI)
+		if err != nil {
+			return fmt.Errorf("Error updating security group %s: %s", id, err)
+		}
+	}
+
+	if d.HasChange("include_members") {
+		err = updateSecurityGroupIncludes(nsxclient, id, securityGroupObject.Members, d.Get("include_members").(*schema.Set))
+		if err != nil {
+			return err
 		}
 	}
 	return resourceSecurityGroupRead(d, m)
@@ -374,40 +605,16 @@ func resourceSecurityGroupDelete(d *schema.ResourceData, m interface{}) error {
 	if d.Get("existing").(bool) || d.Get("no_delete").(bool) {
 		return nil
 	}
-	var name, scopeid string
-
-	// Gather the attributes for the resource.
-	if v, ok := d.GetOk("scopeid"); ok {
-		scopeid = v.(string)
-	} else {
-		return errors.New("scopeid argument is required")
-	}
-
-	if v, ok := d.GetOk("name"); ok {
-		name = v.(string)
-	} else {
-		return errors.New("name argument is required")
-	}
-
-	// Gather all the resources that are associated with the specified
-	// scopeid.
-	log.Printf("[DEBUG] securitygroup.NewGetAll(%s)", scopeid)
-	api := securitygroup.NewGetAll(scopeid)
-	err := nsxclient.Do(api)
+	id := d.Id()
 
+	log.Printf("[DEBUG] newGetSecurityGroup(%s)", id)
+	securityGroupObject, err := getSecurityGroup(id, nsxclient)
 	if err != nil {
 		return err
 	}
 
-	// See if we can find our specifically named resource within the list of
-	// resources associated with the scopeid.
-	log.Printf("[DEBUG] api.GetResponse().FilterByName(\"%s\").ObjectID", name)
-	securityGroupObject, err := getSingleSecurityGroup(scopeid, name, nsxclient) //nolint, maybe a reason to not trigger error
-	id := securityGroupObject.ObjectID
-	log.Printf("[DEBUG] id := %s", id)
-
 	// If the resource has been removed manually, notify Terraform of this fact.
-	if id == "" {
+	if securityGroupObject == nil {
 		d.SetId("")
 		return nil
 	}
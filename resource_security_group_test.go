@@ -0,0 +1,157 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/sky-uk/gonsx/api/securitygroup"
+)
+
+func TestFlattenDynamicMemberDefinition(t *testing.T) {
+	remote := &securitygroup.DynamicMemberDefinition{
+		DynamicSet: []securitygroup.DynamicSet{
+			{
+				Operator: "OR",
+				DynamicCriteria: []securitygroup.DynamicCriteria{
+					{Operator: "AND", Key: "VM.NAME", Criteria: "starts_with", Value: "web"},
+					{Operator: "AND", Key: "VM.SECURITY_TAG", Criteria: "contains", Value: "prod"},
+				},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceSecurityGroup().Schema, map[string]interface{}{})
+	err := d.Set("dynamic_membership", flattenDynamicMemberDefinition(remote))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	built, err := buildDynamicMemberDefinition(d.Get("dynamic_membership"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(built.DynamicSet) != 1 || built.DynamicSet[0].Operator != "OR" {
+		t.Fatalf("unexpected dynamic sets: %+v", built.DynamicSet)
+	}
+
+	criteria := make(map[string]securitygroup.DynamicCriteria)
+	for _, c := range built.DynamicSet[0].DynamicCriteria {
+		criteria[c.Value] = c
+	}
+	for _, expected := range remote.DynamicSet[0].DynamicCriteria {
+		if criteria[expected.Value] != expected {
+			t.Errorf("expected criteria %+v, got %+v", expected, criteria[expected.Value])
+		}
+	}
+
+	if len(flattenDynamicMemberDefinition(nil)) != 0 {
+		t.Errorf("expected no dynamic set for a group without dynamic membership")
+	}
+}
+
+func TestSecurityGroupEntityRules(t *testing.T) {
+	uuid := "7b3c49a2-0e5c-4f2b-9a3d-1c2e3f4a5b6c"
+	cases := []struct {
+		scopeid string
+		value   string
+		valid   bool
+	}{
+		{globalScopeID, "virtualwire-12", true},
+		{globalScopeID, "domain-c7", true},
+		{globalScopeID, "resgroup-v42", true},
+		{globalScopeID, "securitygroup-3", true},
+		{globalScopeID, "securitytag-5", true},
+		{globalScopeID, "network-14", true},
+		{globalScopeID, "directory_group_8", true},
+		{globalScopeID, "502e71fa-1a00-759b-e40f-ce778e915f16.000", true},
+		{universalScopeID, "universalsecuritytag-" + uuid, true},
+		{universalScopeID, "universalwire-3", true},
+		{universalScopeID, "securitytag-5", false},
+	}
+
+	for _, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"scopeid": c.scopeid,
+			"name":    "web",
+			"dynamic_membership": []interface{}{
+				map[string]interface{}{
+					"set_operator":   "OR",
+					"rules_operator": "OR",
+					"rules": []interface{}{
+						map[string]interface{}{"key": "ENTITY", "criteria": "belongs_to", "value": c.value},
+					},
+				},
+			},
+		})
+		_, err := resourceSecurityGroup().Diff(nil, config, nil)
+		if c.valid && err != nil {
+			t.Errorf("%s %s: unexpected error: %s", c.scopeid, c.value, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s %s: expected an error", c.scopeid, c.value)
+		}
+	}
+}
+
+func TestSecurityGroupRuleCriteriaMatrix(t *testing.T) {
+	criteria := []string{"=", "!=", "contains", "starts_with", "ends_with", "similar_to", "belongs_to"}
+	stringCriteria := []string{"=", "!=", "contains", "starts_with", "ends_with", "similar_to"}
+	equality := []string{"=", "!="}
+
+	expected := map[string][]string{
+		"VM.SECURITY_TAG":       stringCriteria,
+		"VM.GUEST_OS_FULL_NAME": stringCriteria,
+		"VM.GUEST_OS_FAMILY":    equality,
+		"VM.GUEST_OS_ID":        equality,
+		"VM.GUEST_HOST_NAME":    stringCriteria,
+		"VM.NAME":               stringCriteria,
+		"COMPUTER_NAME":         stringCriteria,
+		"COMPUTER_OS_NAME":      stringCriteria,
+		"USER_ID":               equality,
+		"ENTITY":                {"belongs_to"},
+	}
+	if len(expected) != len(securityGroupRuleCriteria) {
+		t.Errorf("got %d keys, expected %d", len(securityGroupRuleCriteria), len(expected))
+	}
+
+	for key, accepted := range expected {
+		if _, errs := validateSecurityGroupRuleKey(key, "key"); len(errs) > 0 {
+			t.Errorf("%s: unexpected key error: %v", key, errs)
+		}
+		for _, c := range criteria {
+			valid := false
+			for _, a := range accepted {
+				valid = valid || a == c
+			}
+			err := validateSecurityGroupRule(key, c)
+			if valid && err != nil {
+				t.Errorf("%s %s: unexpected error: %s", key, c, err)
+			}
+			if !valid && err == nil {
+				t.Errorf("%s %s: expected an error", key, c)
+			}
+		}
+	}
+
+	if _, errs := validateSecurityGroupRuleKey("VM.GUEST_OS", "key"); len(errs) == 0 {
+		t.Errorf("VM.GUEST_OS: expected a key error")
+	}
+}
+
+func TestSecurityGroupIDPattern(t *testing.T) {
+	cases := map[string]bool{
+		"securitygroup-12": true,
+		"securitygroup-7b3c49a2-0e5c-4f2b-9a3d-1c2e3f4a5b6c": true,
+		"securitygroup-":    false,
+		"securitygroup-web": false,
+		"ipset-7b3c49a2-0e5c-4f2b-9a3d-1c2e3f4a5b6c": false,
+		"web": false,
+	}
+
+	for id, expected := range cases {
+		if got := securityGroupIDPattern.MatchString(id); got != expected {
+			t.Errorf("%s: got %t, expected %t", id, got, expected)
+		}
+	}
+}
@@ -43,9 +43,10 @@ func resourceService() *schema.Resource {
 			},
 
 			"scopeid": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Scope of the service, globalroot-0 or universalroot-0 for a universal service created on the primary manager",
 			},
 
 			"description": {
@@ -146,14 +147,14 @@ func resourceServiceRead(d *schema.ResourceData, meta interface{}) error {
 	// resources associated with the scopeid.
 	log.Printf("[DEBUG] api.GetResponse().FilterByName(\"%s\").ObjectID", name)
 	serviceObject, err := getSingleService(scopeid, name, nsxclient) //nolint, maybe a reason to not trigger error
-	id := serviceObject.ObjectID
-	log.Printf("[DEBUG] id := %s", id)
 
-	// If the resource has been removed manually, notify Terraform of this fact.
-	if id == "" {
+	// If the resource has been removed manually, or a universal service is
+	// not synchronised to this manager yet, notify Terraform of this fact.
+	if serviceObject == nil || serviceObject.ObjectID == "" {
 		d.SetId("")
+		return nil
 	}
-
+	log.Printf("[DEBUG] id := %s", serviceObject.ObjectID)
 	return nil
 }
 
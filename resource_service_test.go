@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+// TestAccNSXServiceUniversal creates a universal service on the primary
+// manager, NSXSERVER, and reads it from the secondary manager
+// NSXSECONDARYSERVER once synchronised.
+func TestAccNSXServiceUniversal(t *testing.T) {
+	secondary := os.Getenv("NSXSECONDARYSERVER")
+	if secondary == "" {
+		t.Skip("NSXSECONDARYSERVER must be set to test universal services")
+	}
+
+	serviceName := fmt.Sprintf("acctest-nsx-universal-service-%d", acctest.RandInt())
+	testResourceName := "nsx_service.acctest"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNSXServiceUniversalTemplate(serviceName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(testResourceName, "scopeid", universalScopeID),
+					testAccNSXServiceReadFrom(secondary, testResourceName),
+				),
+			},
+		},
+	})
+}
+
+// testAccNSXServiceReadFrom reads a service from another NSX manager.
+func testAccNSXServiceReadFrom(server, resourceName string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("NSX service resource %s not found in resources", resourceName)
+		}
+
+		config := Config{
+			Insecure:    os.Getenv("NSX_ALLOW_UNVERIFIED_SSL") == "true",
+			NSXUserName: os.Getenv("NSXUSERNAME"),
+			NSXPassword: os.Getenv("NSXPASSWORD"),
+			NSXServer:   server,
+		}
+		nsxclient, err := config.Client()
+		if err != nil {
+			return err
+		}
+
+		return resource.Retry(5*time.Minute, func() *resource.RetryError {
+			d := resourceService().Data(rs.Primary)
+			err := resourceServiceRead(d, nsxclient)
+			if err != nil {
+				return resource.NonRetryableError(err)
+			}
+			if d.Id() == "" {
+				return resource.RetryableError(fmt.Errorf("NSX service %s not synchronised to %s", rs.Primary.ID, server))
+			}
+			return nil
+		})
+	}
+}
+
+func testAccNSXServiceUniversalTemplate(name string) string {
+	return fmt.Sprintf(`
+resource "nsx_service" "acctest" {
+name = "%s"
+scopeid = "universalroot-0"
+description = "%s"
+protocol = "TCP"
+ports = "8080"
+}`, name, name)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/sky-uk/gonsx/api"
+	"github.com/sky-uk/gonsx/api/securitygroup"
+)
+
+// gonsx drops the static members of security groups, the objects below
+// carry them along with the rest of the group.
+
+const securityGroupEndpoint = "/api/2.0/services/securitygroup"
+
+// securityGroupList - top level <list> element
+type securityGroupList struct {
+	XMLName        xml.Name        `xml:"list"`
+	SecurityGroups []securityGroup `xml:"securitygroup"`
+}
+
+// securityGroup - <securitygroup> element, securitygroup.SecurityGroup with
+// its description and its included and excluded members.
+type securityGroup struct {
+	securitygroup.SecurityGroup
+	Description    string                `xml:"description,omitempty"`
+	Scope          *securityGroupScope   `xml:"scope,omitempty"`
+	Members        []securityGroupMember `xml:"member,omitempty"`
+	ExcludeMembers []securityGroupMember `xml:"excludeMember,omitempty"`
+}
+
+// securityGroupScope - <scope> element of <securitygroup>
+type securityGroupScope struct {
+	ID             string `xml:"id"`
+	ObjectTypeName string `xml:"objectTypeName,omitempty"`
+	Name           string `xml:"name,omitempty"`
+}
+
+// securityGroupMember - <member> and <excludeMember> elements of <securitygroup>
+type securityGroupMember struct {
+	ObjectID       string `xml:"objectId"`
+	ObjectTypeName string `xml:"objectTypeName,omitempty"`
+	Name           string `xml:"name,omitempty"`
+}
+
+// FilterByName returns the security group of the list named name, or nil.
+func (l securityGroupList) FilterByName(name string) *securityGroup {
+	for i := range l.SecurityGroups {
+		if l.SecurityGroups[i].Name == name {
+			return &l.SecurityGroups[i]
+		}
+	}
+	return nil
+}
+
+// getAllSecurityGroupsAPI api object
+type getAllSecurityGroupsAPI struct {
+	*api.BaseAPI
+}
+
+// newGetAllSecurityGroups returns a new object of getAllSecurityGroupsAPI.
+func newGetAllSecurityGroups(scopeID string) *getAllSecurityGroupsAPI {
+	this := new(getAllSecurityGroupsAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, securityGroupEndpoint+"/scope/"+scopeID, nil, new(securityGroupList))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getAllSecurityGroupsAPI.
+func (ga getAllSecurityGroupsAPI) GetResponse() *securityGroupList {
+	return ga.ResponseObject().(*securityGroupList)
+}
+
+// createSecurityGroupAPI api object
+type createSecurityGroupAPI struct {
+	*api.BaseAPI
+}
+
+// newCreateSecurityGroup returns a new object of createSecurityGroupAPI.
+func newCreateSecurityGroup(scopeID string, group *securityGroup) *createSecurityGroupAPI {
+	this := new(createSecurityGroupAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPost, securityGroupEndpoint+"/bulk/"+scopeID, group, new(string))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of createSecurityGroupAPI, the ID of
+// the new group.
+func (ca createSecurityGroupAPI) GetResponse() string {
+	return ca.ResponseObject().(string)
+}
+
+// updateSecurityGroupAPI api object
+type updateSecurityGroupAPI struct {
+	*api.BaseAPI
+}
+
+// newUpdateSecurityGroup returns a new object of updateSecurityGroupAPI. The
+// whole group is replaced, members included.
+func newUpdateSecurityGroup(group *securityGroup) *updateSecurityGroupAPI {
+	this := new(updateSecurityGroupAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, securityGroupEndpoint+"/bulk/"+group.ObjectID, group, nil)
+
+	return this
+}
+
+// addSecurityGroupMemberAPI api object
+type addSecurityGroupMemberAPI struct {
+	*api.BaseAPI
+}
+
+// newAddSecurityGroupMember returns a new object of addSecurityGroupMemberAPI.
+func newAddSecurityGroupMember(securityGroupID, memberID string) *addSecurityGroupMemberAPI {
+	this := new(addSecurityGroupMemberAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodPut, fmt.Sprintf("%s/%s/members/%s", securityGroupEndpoint, securityGroupID, memberID), nil, nil)
+
+	return this
+}
+
+// removeSecurityGroupMemberAPI api object
+type removeSecurityGroupMemberAPI struct {
+	*api.BaseAPI
+}
+
+// newRemoveSecurityGroupMember returns a new object of removeSecurityGroupMemberAPI.
+func newRemoveSecurityGroupMember(securityGroupID, memberID string) *removeSecurityGroupMemberAPI {
+	this := new(removeSecurityGroupMemberAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodDelete, fmt.Sprintf("%s/%s/members/%s", securityGroupEndpoint, securityGroupID, memberID), nil, nil)
+
+	return this
+}
+
+// securityGroupVMNodes - virtual machines a security group translates to
+type securityGroupVMNodes struct {
+	Nodes []struct {
+		ID   string `xml:"vmId"`
+		Name string `xml:"vmName"`
+	} `xml:"vmnode"`
+}
+
+// securityGroupIPNodes - IP addresses a security group translates to
+type securityGroupIPNodes struct {
+	Nodes []struct {
+		IPAddresses []string `xml:"ipAddresses>string"`
+	} `xml:"ipNode"`
+}
+
+// securityGroupMACNodes - MAC addresses a security group translates to
+type securityGroupMACNodes struct {
+	Nodes []struct {
+		MACAddresses []string `xml:"macAddress>string"`
+	} `xml:"macNode"`
+}
+
+// securityGroupVnicNodes - vNICs a security group translates to
+type securityGroupVnicNodes struct {
+	Nodes []struct {
+		UUID string `xml:"uuid"`
+	} `xml:"vnicnode"`
+}
+
+// getSecurityGroupTranslationAPI api object
+type getSecurityGroupTranslationAPI struct {
+	*api.BaseAPI
+}
+
+// newGetSecurityGroupTranslation returns a new object of
+// getSecurityGroupTranslationAPI. translation is one of virtualmachines,
+// ipaddresses, macaddresses or vnics, response the matching *Nodes object.
+func newGetSecurityGroupTranslation(securityGroupID, translation string, response interface{}) *getSecurityGroupTranslationAPI {
+	this := new(getSecurityGroupTranslationAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, fmt.Sprintf("%s/%s/translation/%s", securityGroupEndpoint, securityGroupID, translation), nil, response)
+
+	return this
+}
+
+// getSecurityGroupAPI api object
+type getSecurityGroupAPI struct {
+	*api.BaseAPI
+}
+
+// newGetSecurityGroup returns a new object of getSecurityGroupAPI.
+func newGetSecurityGroup(securityGroupID string) *getSecurityGroupAPI {
+	this := new(getSecurityGroupAPI)
+	this.BaseAPI = api.NewBaseAPI(http.MethodGet, securityGroupEndpoint+"/"+securityGroupID, nil, new(securityGroup))
+
+	return this
+}
+
+// GetResponse returns a ResponseObject of getSecurityGroupAPI.
+func (ga getSecurityGroupAPI) GetResponse() *securityGroup {
+	return ga.ResponseObject().(*securityGroup)
+}
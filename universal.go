@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Objects of the global scope are local to an NSX manager. Those of the
+// universal scope are created on the primary manager of a cross-vCenter
+// deployment and synchronised, read only, to the secondary managers.
+const (
+	globalScopeID    = "globalroot-0"
+	universalScopeID = "universalroot-0"
+)
+
+// universalIDSuffix is the UUID ending the IDs of most universal objects.
+const universalIDSuffix = `[0-9a-fA-F]{8}(-[0-9a-fA-F]{4}){3}-[0-9a-fA-F]{12}`
+
+// universalObjectIDPattern matches the IDs of universal objects: a type
+// prefix followed by a UUID, e.g. securitygroup-7b3c49a2-..., or a universal
+// logical switch.
+var universalObjectIDPattern = regexp.MustCompile(`^([a-z]+-` + universalIDSuffix + `|universalwire-\d+)$`)
+
+// isUniversalObjectID tells if id is the ID of a universal object.
+func isUniversalObjectID(id string) bool {
+	return universalObjectIDPattern.MatchString(id)
+}
+
+// validateUniversalReference checks that a universal object only references
+// universal objects, values not known at plan time are skipped.
+func validateUniversalReference(value string) error {
+	if value == "" || value == unknownRuleElementValue || isUniversalObjectID(value) {
+		return nil
+	}
+	return fmt.Errorf("%q is not a universal object, universal objects can only reference universal objects", value)
+}